@@ -0,0 +1,56 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ArticleCollectResponse struct {
+	CollectsCount int64 `json:"collects_count"`
+	Collected     bool  `json:"collected"`
+}
+
+// ArticleCollect 收藏文章，重复收藏不会重复计数
+func (a *Article) ArticleCollect(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	articleID, ok := bindPublishedArticle(c)
+	if !ok {
+		return
+	}
+
+	count, err := redis_ser.CollectArticle(articleID, claims.UserID)
+	if err != nil {
+		global.Log.Error("redis_ser.CollectArticle() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "收藏失败")
+		return
+	}
+
+	global.Log.Info("文章收藏成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleCollectResponse{CollectsCount: count, Collected: true})
+}
+
+// ArticleCollectCancel 取消收藏
+func (a *Article) ArticleCollectCancel(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	articleID, ok := bindPublishedArticle(c)
+	if !ok {
+		return
+	}
+
+	count, err := redis_ser.UncollectArticle(articleID, claims.UserID)
+	if err != nil {
+		global.Log.Error("redis_ser.UncollectArticle() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "取消收藏失败")
+		return
+	}
+
+	global.Log.Info("取消收藏成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleCollectResponse{CollectsCount: count, Collected: false})
+}
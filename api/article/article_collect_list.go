@@ -0,0 +1,54 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// ArticleCollectList 当前用户的收藏列表，最近收藏的在前
+func (a *Article) ArticleCollectList(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	var req models.PageInfo
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	ids, err := redis_ser.GetUserCollects(claims.UserID)
+	if err != nil {
+		global.Log.Error("redis_ser.GetUserCollects() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取收藏列表失败")
+		return
+	}
+
+	// 收藏的文章可能之后变为私密或草稿，先过滤再分页，总数只计算可以看到的文章。
+	// 文章恢复可见后仍会出现在收藏列表中，因此不从收藏中删除
+	articles, err := models.NewArticleService().ArticleListByIDs(ids)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleListByIDs() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取收藏列表失败")
+		return
+	}
+	total := int64(len(articles))
+	start := min((req.Page-1)*req.PageSize, len(articles))
+	articles = articles[start:min(start+req.PageSize, len(articles))]
+
+	global.Log.Info("收藏列表成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.SuccessWithPage(c, articles, total, req.Page, req.PageSize)
+}
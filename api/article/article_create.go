@@ -7,8 +7,10 @@ import (
 
 	"blog/global"
 	"blog/models"
+	"blog/models/ctypes"
 	"blog/models/res"
 	"blog/service/redis_ser"
+	"blog/service/sitemap_ser"
 	"blog/utils"
 
 	"github.com/gin-gonic/gin"
@@ -20,8 +22,15 @@ type ArticleRequest struct {
 	Title    string   `json:"title" validate:"required,min=1,max=50"`
 	Abstract string   `json:"abstract" validate:"required,min=1,max=100"`
 	Category []string `json:"category" validate:"required,min=1,max=10,dive,min=1,max=50"`
+	Tags     []string `json:"tags" validate:"omitempty,max=10,dive,min=1,max=20"`
 	Content  string   `json:"content" validate:"required,min=1,max=200000"`
 	CoverID  uint     `json:"cover_id" validate:"required,gt=0"`
+	// 为空时直接发布
+	Status    ctypes.ArticleStatus `json:"status" validate:"omitempty,oneof=draft published scheduled"`
+	PublishAt ctypes.MyTime        `json:"publish_at"`
+	// 为空时公开，密码保护时需要同时设置访问密码
+	Visibility ctypes.Visibility `json:"visibility" validate:"omitempty,oneof=public unlisted private password"`
+	Password   string            `json:"password" validate:"omitempty,min=4,max=32"`
 }
 
 func (a *Article) ArticleCreate(c *gin.Context) {
@@ -42,20 +51,6 @@ func (a *Article) ArticleCreate(c *gin.Context) {
 	_claims, _ := c.Get("claims")
 	claims := _claims.(*utils.CustomClaims)
 	userID := claims.UserID
-	html, err := utils.ConvertMarkdownToHTML(req.Content)
-	if err != nil {
-		global.Log.Error("utils.ConvertMarkdownToHTML() failed", zap.String("error", err.Error()))
-		res.Error(c, res.ServerError, "utils.ConvertMarkdownToHTML失败")
-		return
-	}
-
-	content, err := utils.ConvertHTMLToMarkdown(html)
-	if err != nil {
-		global.Log.Error("utils.ConvertHTMLToMarkdown() failed", zap.String("error", err.Error()))
-		res.Error(c, res.ServerError, "utils.ConvertHTMLToMarkdown失败")
-		return
-	}
-
 	if req.CoverID == 0 {
 		var imageIDList []uint
 		global.DB.Model(models.ImageModel{}).Select("id").Scan(&imageIDList)
@@ -97,20 +92,59 @@ func (a *Article) ArticleCreate(c *gin.Context) {
 		Title:    req.Title,
 		Abstract: req.Abstract,
 		Category: req.Category,
-		Content:  content,
+		Tags:     req.Tags,
+		Content:  req.Content,
 		CoverID:  req.CoverID,
 		CoverURL: coverUrl,
 		UserID:   userID,
 		UserName: user.Nickname,
 	}
+	err = article.StatusApply(req.Status, req.PublishAt)
+	if err != nil {
+		global.Log.Error("article.StatusApply() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = ctypes.VisibilityPublic
+	}
+	err = article.VisibilityApply(req.Visibility, req.Password)
+	if err != nil {
+		global.Log.Error("article.VisibilityApply() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+	err = models.TagEnsure(req.Tags)
+	if err != nil {
+		global.Log.Error("models.TagEnsure() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "保存标签失败")
+		return
+	}
+	err = article.Render()
+	if err != nil {
+		global.Log.Error("article.Render() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "渲染文章内容失败")
+		return
+	}
 	articleService := models.NewArticleService()
+	err = articleService.ArticleSlugAssign(&article)
+	if err != nil {
+		global.Log.Error("articleService.ArticleSlugAssign() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "生成文章链接失败")
+		return
+	}
 	err = articleService.ArticleCreate(&article)
 	if err != nil {
 		global.Log.Error("articleService.ArticleCreate() failed", zap.String("error", err.Error()))
 		res.Error(c, res.ServerError, "创建文章失败")
 		return
 	}
+	err = models.RevisionCreate(&article, userID)
+	if err != nil {
+		global.Log.Error("models.RevisionCreate() failed", zap.String("error", err.Error()))
+	}
 	redis_ser.AddToBloomFilter(articleID)
+	sitemap_ser.MarkDirty()
 	global.Log.Info("创建文章成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
 	res.Success(c, nil)
 }
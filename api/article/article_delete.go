@@ -5,6 +5,7 @@ import (
 	"blog/models"
 	"blog/models/res"
 	"blog/service/redis_ser"
+	"blog/service/sitemap_ser"
 	"blog/utils"
 
 	"github.com/gin-gonic/gin"
@@ -39,13 +40,36 @@ func (a *Article) ArticleDelete(c *gin.Context) {
 		return
 	}
 
+	err = models.RevisionDelete(req.IDList)
+	if err != nil {
+		global.Log.Error("models.RevisionDelete() failed", zap.String("error", err.Error()))
+	}
+
+	err = models.SeriesRemoveArticles(req.IDList)
+	if err != nil {
+		global.Log.Error("models.SeriesRemoveArticles() failed", zap.String("error", err.Error()))
+	}
+
 	for _, articleID := range req.IDList {
 		err = redis_ser.DeleteArticleStats(articleID)
 		if err != nil {
 			global.Log.Error("redis_ser.DeleteArticleStats() failed", zap.String("error", err.Error()))
 		}
+		err = redis_ser.DeleteRelatedArticles(articleID)
+		if err != nil {
+			global.Log.Error("redis_ser.DeleteRelatedArticles() failed", zap.String("error", err.Error()))
+		}
+		err = redis_ser.DeleteArticleInteractions(articleID)
+		if err != nil {
+			global.Log.Error("redis_ser.DeleteArticleInteractions() failed", zap.String("error", err.Error()))
+		}
+		err = redis_ser.RemoveHotArticle(articleID)
+		if err != nil {
+			global.Log.Error("redis_ser.RemoveHotArticle() failed", zap.String("error", err.Error()))
+		}
 
 	}
+	sitemap_ser.MarkDirty()
 	global.Log.Info("文章删除成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
 	res.Success(c, nil)
 }
@@ -16,6 +16,14 @@ type ArticleDetailRequest struct {
 	ID string `uri:"id" validate:"required"`
 }
 
+// ArticleDetailResponse 文章详情，属于系列时附带系列内的上一篇、下一篇，
+// 密码保护的文章未解锁时 locked 为 true 且不返回正文
+type ArticleDetailResponse struct {
+	*models.Article
+	Series *models.SeriesNav `json:"series,omitempty"`
+	Locked bool              `json:"locked,omitempty"`
+}
+
 func (a *Article) ArticleDetail(c *gin.Context) {
 	var req ArticleDetailRequest
 	err := c.ShouldBindUri(&req)
@@ -39,7 +47,38 @@ func (a *Article) ArticleDetail(c *gin.Context) {
 		return
 	}
 
-	redis_ser.IncrArticleLookCount(req.ID, c.ClientIP())
+	articleDetailRespond(c, article)
+}
+
+// articleDetailRespond 检查文章可见性，附带系列导航并增加浏览量后返回文章详情
+func articleDetailRespond(c *gin.Context, article *models.Article) {
+	visible, locked := articleAccess(c, article)
+	if !visible {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+	if locked {
+		article.Content, article.ContentHTML, article.Toc = "", "", nil
+		global.Log.Info("文章需要密码", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+		res.Success(c, ArticleDetailResponse{Article: article, Locked: true})
+		return
+	}
+
+	// 早期保存的文章没有渲染结果，读取时补充渲染
+	if article.ContentHTML == "" && article.Content != "" {
+		err := article.Render()
+		if err != nil {
+			global.Log.Error("article.Render() failed", zap.String("error", err.Error()))
+		}
+	}
+
+	series, err := models.SeriesNavGet(article)
+	if err != nil {
+		// 系列导航获取失败不影响文章详情
+		global.Log.Error("models.SeriesNavGet() failed", zap.String("error", err.Error()))
+	}
+
+	redis_ser.IncrArticleLookCount(article.ID, c.ClientIP())
 	global.Log.Info("文章详情成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
-	res.Success(c, article)
+	res.Success(c, ArticleDetailResponse{Article: article, Series: series})
 }
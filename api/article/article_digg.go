@@ -0,0 +1,56 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ArticleDiggResponse struct {
+	DiggCount int64 `json:"digg_count"`
+	Digged    bool  `json:"digged"`
+}
+
+// ArticleDigg 点赞文章，重复点赞不会重复计数
+func (a *Article) ArticleDigg(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	articleID, ok := bindPublishedArticle(c)
+	if !ok {
+		return
+	}
+
+	count, err := redis_ser.DiggArticle(articleID, claims.UserID)
+	if err != nil {
+		global.Log.Error("redis_ser.DiggArticle() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "点赞失败")
+		return
+	}
+
+	global.Log.Info("文章点赞成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleDiggResponse{DiggCount: count, Digged: true})
+}
+
+// ArticleDiggCancel 取消点赞
+func (a *Article) ArticleDiggCancel(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	articleID, ok := bindPublishedArticle(c)
+	if !ok {
+		return
+	}
+
+	count, err := redis_ser.UndiggArticle(articleID, claims.UserID)
+	if err != nil {
+		global.Log.Error("redis_ser.UndiggArticle() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "取消点赞失败")
+		return
+	}
+
+	global.Log.Info("取消点赞成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleDiggResponse{DiggCount: count, Digged: false})
+}
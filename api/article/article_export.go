@@ -0,0 +1,140 @@
+package article
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/export_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// 一次最多导出的文章数
+const articleExportMaxArticles = 50
+
+// ArticleExportRequest 导出参数，ids 为追加在当前文章之后的其他文章，可重复传递或用逗号分隔
+type ArticleExportRequest struct {
+	Format string   `form:"format" validate:"required,oneof=epub html"`
+	IDs    []string `form:"ids"`
+	Title  string   `form:"title" validate:"max=100"`
+}
+
+// ArticleExport 将文章导出为 EPUB 或单文件 HTML，传入多篇文章时按顺序合并为一本书
+func (a *Article) ArticleExport(c *gin.Context) {
+	var uri ArticleDetailRequest
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req ArticleExportRequest
+	err = c.ShouldBindQuery(&req)
+	if err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err = utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	ids := []string{uri.ID}
+	for _, value := range req.IDs {
+		for _, id := range strings.Split(value, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) > articleExportMaxArticles {
+		res.Error(c, res.InvalidParameter, "一次最多导出50篇文章")
+		return
+	}
+
+	articleService := models.NewArticleService()
+	articles := make([]models.Article, 0, len(ids))
+	for _, id := range ids {
+		article, err := articleService.ArticleGet(id)
+		if err != nil {
+			global.Log.Error("models.NewArticleService().ArticleGet() failed", zap.String("error", err.Error()))
+			res.Error(c, res.NotFound, "文章不存在")
+			return
+		}
+		visible, locked := articleAccess(c, article)
+		if !visible {
+			res.Error(c, res.NotFound, "文章不存在")
+			return
+		}
+		if locked {
+			res.Error(c, res.Forbidden, "请先输入文章密码")
+			return
+		}
+		articles = append(articles, *article)
+	}
+
+	title := req.Title
+	if title == "" {
+		title = articleExportTitle(articles)
+	}
+	book, err := export_ser.NewBook(title, articles)
+	if err != nil {
+		global.Log.Error("export_ser.NewBook() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "导出失败")
+		return
+	}
+
+	var data []byte
+	var contentType string
+	switch req.Format {
+	case "epub":
+		data, err = book.EPUB()
+		contentType = "application/epub+zip"
+	default:
+		data, err = book.HTML()
+		contentType = "text/html; charset=utf-8"
+	}
+	if err != nil {
+		global.Log.Error("导出文章失败", zap.String("format", req.Format), zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "导出失败")
+		return
+	}
+
+	global.Log.Info("导出文章成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": title + "." + req.Format,
+	}))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// articleExportTitle 默认书名：单篇文章使用文章标题，同一系列的多篇文章使用系列标题
+func articleExportTitle(articles []models.Article) string {
+	first := articles[0]
+	if len(articles) == 1 || first.SeriesID == 0 {
+		return first.Title
+	}
+	for _, article := range articles[1:] {
+		if article.SeriesID != first.SeriesID {
+			return first.Title
+		}
+	}
+	series, err := models.SeriesGet(first.SeriesID)
+	if err != nil {
+		global.Log.Error("models.SeriesGet() failed", zap.String("error", err.Error()))
+		return first.Title
+	}
+	return series.Title
+}
@@ -0,0 +1,63 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleFeatureRequest struct {
+	Featured bool `json:"featured"`
+}
+
+// ArticleFeature 设置或取消精选文章
+func (a *Article) ArticleFeature(c *gin.Context) {
+	var uri ArticleDetailRequest
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req ArticleFeatureRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err = utils.Validate(uri)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	articleService := models.NewArticleService()
+	exist, err := articleService.ArticleExist(uri.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleExist() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "设置失败")
+		return
+	}
+	if !exist {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+
+	err = articleService.ArticleFeatureSet(uri.ID, req.Featured)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleFeatureSet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "设置失败")
+		return
+	}
+
+	global.Log.Info("文章精选设置成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, nil)
+}
@@ -0,0 +1,76 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleHotRequest struct {
+	Window string `form:"window" validate:"omitempty,oneof=7d 30d"`
+	Size   int    `form:"size" validate:"omitempty,gt=0,lte=50"`
+}
+
+// ArticleHotItem 热门文章，附带窗口内的热度分数
+type ArticleHotItem struct {
+	models.Article
+	HotScore float64 `json:"hot_score"`
+}
+
+func (a *Article) ArticleHot(c *gin.Context) {
+	var req ArticleHotRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	if req.Window == "" {
+		req.Window = "7d"
+	}
+	if req.Size == 0 {
+		req.Size = 10
+	}
+
+	ranks, err := redis_ser.GetHotArticles(req.Window, req.Size)
+	if err != nil {
+		global.Log.Error("redis_ser.GetHotArticles() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取热门文章失败")
+		return
+	}
+
+	ids := make([]string, 0, len(ranks))
+	scores := make(map[string]float64, len(ranks))
+	for _, rank := range ranks {
+		id, _ := rank.Member.(string)
+		ids = append(ids, id)
+		scores[id] = rank.Score
+	}
+
+	articles, err := models.NewArticleService().ArticleListByIDs(ids)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleListByIDs() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取热门文章失败")
+		return
+	}
+
+	list := make([]ArticleHotItem, 0, len(articles))
+	for _, article := range articles {
+		list = append(list, ArticleHotItem{Article: article, HotScore: scores[article.ID]})
+	}
+
+	global.Log.Info("热门文章成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, list)
+}
@@ -15,6 +15,18 @@ type ArticleListRequest struct {
 	models.SearchParams
 }
 
+// ArticleListResponse 文章列表响应，在分页数据之外附带分类和月份统计
+type ArticleListResponse struct {
+	res.PageData[[]ArticleListItem]
+	Facets *models.SearchFacets `json:"facets"`
+}
+
+// ArticleListItem 文章列表项，搜索关键词时附带命中的高亮片段
+type ArticleListItem struct {
+	models.Article
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
 func (a *Article) ArticleList(c *gin.Context) {
 	var req ArticleListRequest
 	err := c.ShouldBindJSON(&req)
@@ -38,13 +50,26 @@ func (a *Article) ArticleList(c *gin.Context) {
 		req.PageSize = 10
 	}
 
+	req.IsAdmin = isAdmin(c)
+	req.UserID = currentUserID(c)
+	req.WithFacets = true
 	articles, err := models.NewArticleService().ArticleSearch(req.SearchParams)
 	if err != nil {
 		global.Log.Error("models.NewArticleService().ArticleSearch() failed", zap.String("error", err.Error()))
 		res.Error(c, res.ServerError, "搜索文章失败")
 		return
 	}
+	list := make([]ArticleListItem, 0, len(articles.Articles))
+	for _, article := range articles.Articles {
+		list = append(list, ArticleListItem{
+			Article:   article,
+			Highlight: articles.Highlights[article.ID],
+		})
+	}
 	global.Log.Info("文章列表成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
 
-	res.SuccessWithPage(c, articles.Articles, articles.Total, req.Page, req.PageSize)
+	res.Success(c, ArticleListResponse{
+		PageData: res.NewPageData(list, articles.Total, req.Page, req.PageSize),
+		Facets:   articles.Facets,
+	})
 }
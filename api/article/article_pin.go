@@ -0,0 +1,72 @@
+package article
+
+import (
+	"time"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// ArticlePinRequest 置顶参数，pinned_until 为空表示一直置顶
+type ArticlePinRequest struct {
+	Pinned      bool           `json:"pinned"`
+	PinnedUntil *ctypes.MyTime `json:"pinned_until"`
+}
+
+// ArticlePin 置顶或取消置顶文章
+func (a *Article) ArticlePin(c *gin.Context) {
+	var uri ArticleDetailRequest
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req ArticlePinRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err = utils.Validate(uri)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	if req.Pinned && req.PinnedUntil != nil && !time.Time(*req.PinnedUntil).After(time.Now()) {
+		res.Error(c, res.InvalidParameter, "置顶到期时间必须晚于当前时间")
+		return
+	}
+
+	articleService := models.NewArticleService()
+	exist, err := articleService.ArticleExist(uri.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleExist() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "设置失败")
+		return
+	}
+	if !exist {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+
+	err = articleService.ArticlePinSet(uri.ID, req.Pinned, req.PinnedUntil)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticlePinSet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "设置失败")
+		return
+	}
+
+	global.Log.Info("文章置顶设置成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, nil)
+}
@@ -0,0 +1,83 @@
+package article
+
+import (
+	"encoding/json"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// relatedArticlesMax 缓存的相关文章数量，请求的数量不能超过它
+const relatedArticlesMax = 10
+
+type ArticleRelatedRequest struct {
+	N int `form:"n" validate:"omitempty,gt=0,lte=10"`
+}
+
+func (a *Article) ArticleRelated(c *gin.Context) {
+	// 文章不存在或当前请求无权访问时不返回相关文章
+	id, ok := bindPublishedArticle(c)
+	if !ok {
+		return
+	}
+
+	var req ArticleRelatedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	if req.N == 0 {
+		req.N = 5
+	}
+
+	// 缓存中只保存文章ID，读取时重新获取文章，缓存之后变为私密、未发布或被删除的文章会被过滤掉
+	var articles []models.Article
+	var ids []string
+	cached, err := redis_ser.GetRelatedArticles(id)
+	if err == nil && json.Unmarshal(cached, &ids) == nil {
+		global.Log.Info("相关文章命中缓存", zap.String("id", id))
+		articles, err = models.NewArticleService().ArticleListByIDs(ids)
+		if err != nil {
+			global.Log.Error("models.NewArticleService().ArticleListByIDs() failed", zap.String("error", err.Error()))
+			res.Error(c, res.ServerError, "获取相关文章失败")
+			return
+		}
+	} else {
+		articles, err = models.NewArticleService().RelatedArticles(id, relatedArticlesMax)
+		if err != nil {
+			global.Log.Error("models.NewArticleService().RelatedArticles() failed", zap.String("error", err.Error()))
+			res.Error(c, res.ServerError, "获取相关文章失败")
+			return
+		}
+		ids = make([]string, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.ID)
+		}
+		if data, err := json.Marshal(ids); err == nil {
+			if err := redis_ser.SetRelatedArticles(id, data); err != nil {
+				global.Log.Error("redis_ser.SetRelatedArticles() failed", zap.String("error", err.Error()))
+			}
+		}
+	}
+
+	if len(articles) > req.N {
+		articles = articles[:req.N]
+	}
+	global.Log.Info("相关文章成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, articles)
+}
@@ -0,0 +1,42 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleRevisionRequest struct {
+	ID      string `uri:"id" validate:"required"`
+	Version int64  `uri:"version" validate:"required,gt=0"`
+}
+
+func (a *Article) ArticleRevisionDetail(c *gin.Context) {
+	var req ArticleRevisionRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	revision, err := models.RevisionGet(req.ID, req.Version)
+	if err != nil {
+		global.Log.Error("models.RevisionGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "修订记录不存在")
+		return
+	}
+	global.Log.Info("修订记录详情成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, revision)
+}
@@ -0,0 +1,69 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleRevisionDiffRequest struct {
+	ID   string `uri:"id" validate:"required"`
+	From int64  `form:"from" validate:"required,gt=0"`
+	To   int64  `form:"to" validate:"required,gt=0"`
+}
+
+type ArticleRevisionDiffResponse struct {
+	From     int64            `json:"from"`
+	To       int64            `json:"to"`
+	Title    []utils.DiffLine `json:"title"`
+	Abstract []utils.DiffLine `json:"abstract"`
+	Content  []utils.DiffLine `json:"content"`
+}
+
+func (a *Article) ArticleRevisionDiff(c *gin.Context) {
+	var req ArticleRevisionDiffRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	from, err := models.RevisionGet(req.ID, req.From)
+	if err != nil {
+		global.Log.Error("models.RevisionGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "修订记录不存在")
+		return
+	}
+	to, err := models.RevisionGet(req.ID, req.To)
+	if err != nil {
+		global.Log.Error("models.RevisionGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "修订记录不存在")
+		return
+	}
+
+	global.Log.Info("修订记录对比成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleRevisionDiffResponse{
+		From:     req.From,
+		To:       req.To,
+		Title:    utils.DiffLines(from.Title, to.Title),
+		Abstract: utils.DiffLines(from.Abstract, to.Abstract),
+		Content:  utils.DiffLines(from.Content, to.Content),
+	})
+}
@@ -0,0 +1,47 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleRevisionListRequest struct {
+	ID string `uri:"id" validate:"required"`
+	models.PageInfo
+}
+
+func (a *Article) ArticleRevisionList(c *gin.Context) {
+	var req ArticleRevisionListRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	list, count, err := models.RevisionList(req.ID, req.PageInfo)
+	if err != nil {
+		global.Log.Error("models.RevisionList() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取修订记录失败")
+		return
+	}
+	global.Log.Info("修订记录列表成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.SuccessWithPage(c, list, count, req.Page, req.PageSize)
+}
@@ -0,0 +1,119 @@
+package article
+
+import (
+	"errors"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/sitemap_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleRevisionRestoreRequest struct {
+	Version int64 `json:"version" validate:"required,gt=0"` // 客户端最后看到的版本号
+}
+
+// ArticleRevisionRestore 将文章恢复到指定版本，恢复结果作为一个新版本保存
+func (a *Article) ArticleRevisionRestore(c *gin.Context) {
+	var req ArticleRevisionRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var body ArticleRevisionRestoreRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	err = utils.Validate(body)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+
+	revision, err := models.RevisionGet(req.ID, req.Version)
+	if err != nil {
+		global.Log.Error("models.RevisionGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "修订记录不存在")
+		return
+	}
+
+	articleService := models.NewArticleService()
+	article, err := articleService.ArticleGet(req.ID)
+	if err != nil {
+		global.Log.Error("articleService.ArticleGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取文章数据失败")
+		return
+	}
+	if article.Version != body.Version {
+		articleVersionConflict(c, req.ID, body.Version, article)
+		return
+	}
+	article.Title = revision.Title
+	article.Abstract = revision.Abstract
+	article.Content = revision.Content
+	article.Category = revision.Category
+	article.Tags = revision.Tags
+	article.CoverID = revision.CoverID
+	article.CoverURL = revision.CoverURL
+	// 修订记录中的标签和分类可能已被删除或改名，恢复时重新创建
+	err = models.TagEnsure(article.Tags)
+	if err != nil {
+		global.Log.Error("models.TagEnsure() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "保存标签失败")
+		return
+	}
+	err = models.CategoryEnsure(article.Category)
+	if err != nil {
+		global.Log.Error("models.CategoryEnsure() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "保存分类失败")
+		return
+	}
+	err = article.Render()
+	if err != nil {
+		global.Log.Error("article.Render() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "渲染文章内容失败")
+		return
+	}
+	err = articleService.ArticleSlugAssign(article)
+	if err != nil {
+		global.Log.Error("articleService.ArticleSlugAssign() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "生成文章链接失败")
+		return
+	}
+	err = articleService.ArticleRevise(article, body.Version, claims.UserID)
+	if errors.Is(err, models.ErrVersionConflict) {
+		current, getErr := articleService.ArticleGet(req.ID)
+		if getErr != nil {
+			global.Log.Error("articleService.ArticleGet() failed", zap.String("error", getErr.Error()))
+		}
+		articleVersionConflict(c, req.ID, body.Version, current)
+		return
+	}
+	if err != nil {
+		global.Log.Error("articleService.ArticleRevise() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "文章恢复失败")
+		return
+	}
+
+	sitemap_ser.MarkDirty()
+	global.Log.Info("文章恢复成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, article)
+}
@@ -0,0 +1,63 @@
+package article
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleSlugRequest struct {
+	Slug string `uri:"slug" validate:"required,max=100"`
+}
+
+// ArticleSlug 根据 slug 获取文章详情，旧 slug 重定向到当前 slug。
+// 旧 slug 之后可能被其他文章使用，因此使用不会被缓存的临时重定向
+func (a *Article) ArticleSlug(c *gin.Context) {
+	var req ArticleSlugRequest
+	err := c.ShouldBindUri(&req)
+	if err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err = utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	article, redirected, err := models.NewArticleService().ArticleGetBySlug(req.Slug)
+	if errors.Is(err, models.ErrArticleNotFound) {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleGetBySlug() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "加载失败")
+		return
+	}
+
+	// 先检查访问权限，不能通过重定向暴露私密文章和草稿的 slug
+	if visible, _ := articleAccess(c, article); !visible {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+	if redirected {
+		// 保留查询参数，如密码保护文章的 unlock_token
+		location := url.URL{Path: "/api/article/slug/" + article.Slug, RawQuery: c.Request.URL.RawQuery}
+		c.Redirect(http.StatusFound, location.String())
+		return
+	}
+	articleDetailRespond(c, article)
+}
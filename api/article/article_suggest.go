@@ -0,0 +1,45 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleSuggestRequest struct {
+	Q    string `form:"q" validate:"required,max=50"`
+	Size int    `form:"size" validate:"omitempty,gt=0,lte=20"`
+}
+
+func (a *Article) ArticleSuggest(c *gin.Context) {
+	var req ArticleSuggestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	if req.Size == 0 {
+		req.Size = 8
+	}
+
+	suggestions, err := models.NewArticleService().ArticleSuggest(req.Q, req.Size)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleSuggest() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取输入提示失败")
+		return
+	}
+
+	res.Success(c, suggestions)
+}
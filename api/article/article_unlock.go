@@ -0,0 +1,96 @@
+package article
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ArticleUnlockRequest struct {
+	Password string `json:"password" validate:"required,max=32"`
+}
+
+// ArticleUnlockResponse 解锁令牌，访问文章详情时放在请求头 X-Article-Token 中
+type ArticleUnlockResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expires_in"` // 有效期，单位秒
+}
+
+// ArticleUnlock 校验密码保护文章的访问密码，正确时签发短期有效的解锁令牌
+func (a *Article) ArticleUnlock(c *gin.Context) {
+	var uri ArticleDetailRequest
+	err := c.ShouldBindUri(&uri)
+	if err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req ArticleUnlockRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err = utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	articleService := models.NewArticleService()
+	article, err := articleService.ArticleGet(uri.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+	if !article.IsPublished() || article.Visibility != ctypes.VisibilityPassword {
+		res.Error(c, res.NotFound, "文章不存在")
+		return
+	}
+
+	attempts, err := redis_ser.IncrUnlockAttempts(article.ID, c.ClientIP())
+	if err != nil {
+		global.Log.Error("redis_ser.IncrUnlockAttempts() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "验证失败")
+		return
+	}
+	if attempts > redis_ser.UnlockAttemptMax {
+		res.Error(c, res.TooManyRequests, "尝试次数过多，请稍后再试")
+		return
+	}
+
+	hash, err := articleService.ArticlePasswordHash(article.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticlePasswordHash() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "验证失败")
+		return
+	}
+	if hash == "" || !utils.CheckPassword(hash, req.Password) {
+		res.Error(c, res.PasswordError, "密码错误")
+		return
+	}
+
+	token, err := utils.GenerateArticleToken(article.ID, hash)
+	if err != nil {
+		global.Log.Error("utils.GenerateArticleToken() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "生成令牌失败")
+		return
+	}
+
+	global.Log.Info("文章解锁成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, ArticleUnlockResponse{
+		Token:     token,
+		ExpiresIn: int64(utils.ArticleTokenExpires.Seconds()),
+	})
+}
@@ -1,9 +1,14 @@
 package article
 
 import (
+	"errors"
+
 	"blog/global"
 	"blog/models"
+	"blog/models/ctypes"
 	"blog/models/res"
+	"blog/service/redis_ser"
+	"blog/service/sitemap_ser"
 	"blog/utils"
 
 	"github.com/gin-gonic/gin"
@@ -13,11 +18,19 @@ import (
 
 type ArticleUpdateRequest struct {
 	ID       string   `json:"id" validate:"required"`
+	Version  int64    `json:"version" validate:"required,gt=0"` // 客户端最后看到的版本号
 	Title    string   `json:"title" validate:"required,min=1,max=50"`
 	Abstract string   `json:"abstract" validate:"required,min=1,max=100"`
 	Content  string   `json:"content" validate:"required,min=1,max=100000"`
 	Category []string `json:"category" validate:"required,min=1,max=10,dive,min=1,max=10"`
+	Tags     []string `json:"tags" validate:"omitempty,max=10,dive,min=1,max=20"`
 	CoverID  uint     `json:"cover_id" validate:"required,gt=0"`
+	// 为空时保持原状态
+	Status    ctypes.ArticleStatus `json:"status" validate:"omitempty,oneof=draft published scheduled archived"`
+	PublishAt ctypes.MyTime        `json:"publish_at"`
+	// 为空时保持原可见性，已经是密码保护的文章不传密码时沿用原密码
+	Visibility ctypes.Visibility `json:"visibility" validate:"omitempty,oneof=public unlisted private password"`
+	Password   string            `json:"password" validate:"omitempty,min=4,max=32"`
 }
 
 func (a *Article) ArticleUpdate(c *gin.Context) {
@@ -52,18 +65,80 @@ func (a *Article) ArticleUpdate(c *gin.Context) {
 		res.Error(c, res.ServerError, "获取文章数据失败")
 		return
 	}
+	if article.Version != req.Version {
+		articleVersionConflict(c, req.ID, req.Version, article)
+		return
+	}
 	article.Title = req.Title
 	article.Abstract = req.Abstract
 	article.Content = req.Content
 	article.Category = req.Category
+	article.Tags = req.Tags
 	article.CoverID = req.CoverID
 	article.CoverURL = coverUrl
-	err = models.NewArticleService().ArticleUpdate(article)
+	if req.Status != "" {
+		err = article.StatusApply(req.Status, req.PublishAt)
+		if err != nil {
+			global.Log.Error("article.StatusApply() failed", zap.String("error", err.Error()))
+			res.Error(c, res.InvalidParameter, err.Error())
+			return
+		}
+	}
+	if req.Visibility != "" {
+		err = article.VisibilityApply(req.Visibility, req.Password)
+		if err != nil {
+			global.Log.Error("article.VisibilityApply() failed", zap.String("error", err.Error()))
+			res.Error(c, res.InvalidParameter, err.Error())
+			return
+		}
+	}
+	err = models.TagEnsure(req.Tags)
+	if err != nil {
+		global.Log.Error("models.TagEnsure() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "保存标签失败")
+		return
+	}
+	err = article.Render()
 	if err != nil {
-		global.Log.Error("models.NewArticleService().UpdateArticle() failed", zap.String("error", err.Error()))
+		global.Log.Error("article.Render() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "渲染文章内容失败")
+		return
+	}
+	err = models.NewArticleService().ArticleSlugAssign(article)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleSlugAssign() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "生成文章链接失败")
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*utils.CustomClaims)
+	err = models.NewArticleService().ArticleRevise(article, req.Version, claims.UserID)
+	if errors.Is(err, models.ErrVersionConflict) {
+		current, getErr := models.NewArticleService().ArticleGet(req.ID)
+		if getErr != nil {
+			global.Log.Error("models.NewArticleService().ArticleGet() failed", zap.String("error", getErr.Error()))
+		}
+		articleVersionConflict(c, req.ID, req.Version, current)
+		return
+	}
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleRevise() failed", zap.String("error", err.Error()))
 		res.Error(c, res.ServerError, "文章更新失败")
 		return
 	}
+
+	err = redis_ser.DeleteRelatedArticles(article.ID)
+	if err != nil {
+		global.Log.Error("redis_ser.DeleteRelatedArticles() failed", zap.String("error", err.Error()))
+	}
+	sitemap_ser.MarkDirty()
 	global.Log.Info("文章更新成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
-	res.Success(c, nil)
+	// 返回更新后的文章，客户端以其中的版本号继续编辑
+	res.Success(c, article)
+}
+
+// articleVersionConflict 返回服务端的最新版本，便于客户端合并修改
+func articleVersionConflict(c *gin.Context, id string, version int64, current *models.Article) {
+	global.Log.Warn("文章版本冲突", zap.String("id", id), zap.Int64("version", version))
+	res.ErrorWithData(c, res.ArticleVersionConflict, res.GetMsg(res.ArticleVersionConflict), current)
 }
@@ -1,4 +1,114 @@
 package article
 
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
 type Article struct {
 }
+
+// isAdmin 当前请求是否来自管理员
+func isAdmin(c *gin.Context) bool {
+	_claims, exists := c.Get("claims")
+	if !exists {
+		return false
+	}
+	claims, ok := _claims.(*utils.CustomClaims)
+	return ok && claims.Role == ctypes.RoleAdmin
+}
+
+// currentUserID 当前登录用户的id，未登录时为 0
+func currentUserID(c *gin.Context) uint {
+	_claims, exists := c.Get("claims")
+	if !exists {
+		return 0
+	}
+	claims, ok := _claims.(*utils.CustomClaims)
+	if !ok {
+		return 0
+	}
+	return claims.UserID
+}
+
+// canManage 当前用户是否为管理员或文章作者
+func canManage(c *gin.Context, article *models.Article) bool {
+	if isAdmin(c) {
+		return true
+	}
+	userID := currentUserID(c)
+	return userID != 0 && userID == article.UserID
+}
+
+// articleUnlocked 请求是否带有该文章有效的解锁令牌，令牌可以放在请求头 X-Article-Token 或查询参数 unlock_token 中
+func articleUnlocked(c *gin.Context, article *models.Article) bool {
+	token := c.GetHeader("X-Article-Token")
+	if token == "" {
+		token = c.Query("unlock_token")
+	}
+	if token == "" {
+		return false
+	}
+	hash, err := models.NewArticleService().ArticlePasswordHash(article.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticlePasswordHash() failed", zap.String("error", err.Error()))
+		return false
+	}
+	return hash != "" && utils.CheckArticleToken(token, article.ID, hash)
+}
+
+// articleAccess 检查当前请求能否访问文章：未发布和私密文章对其他人不可见，
+// 密码保护的文章在没有解锁令牌时 locked 为 true，只能看到标题等基本信息
+func articleAccess(c *gin.Context, article *models.Article) (visible, locked bool) {
+	if !article.IsPublished() && !isAdmin(c) {
+		return false, false
+	}
+	switch article.Visibility {
+	case ctypes.VisibilityPrivate:
+		return canManage(c, article), false
+	case ctypes.VisibilityPassword:
+		return true, !canManage(c, article) && !articleUnlocked(c, article)
+	}
+	return true, false
+}
+
+// bindPublishedArticle 解析路径中的文章ID并确认文章已发布且可以访问，失败时直接写入错误响应
+func bindPublishedArticle(c *gin.Context) (string, bool) {
+	var req ArticleDetailRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return "", false
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return "", false
+	}
+
+	article, err := models.NewArticleService().ArticleGet(req.ID)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "文章不存在")
+		return "", false
+	}
+	visible, locked := articleAccess(c, article)
+	if !article.IsPublished() || !visible {
+		res.Error(c, res.NotFound, "文章不存在")
+		return "", false
+	}
+	if locked {
+		res.Error(c, res.Forbidden, "请先输入文章密码")
+		return "", false
+	}
+	return req.ID, true
+}
@@ -12,7 +12,8 @@ import (
 )
 
 type CategoryCreate struct {
-	Name string `json:"name" validate:"required,min=1,max=10"`
+	Name     string `json:"name" validate:"required,min=1,max=10"`
+	ParentID *uint  `json:"parent_id" validate:"omitempty,gt=0"`
 }
 
 func (cg *Category) CategoryCreate(c *gin.Context) {
@@ -30,8 +31,27 @@ func (cg *Category) CategoryCreate(c *gin.Context) {
 		return
 	}
 
+	exists, err := models.CategoryNameExists(req.Name, 0)
+	if err != nil {
+		global.Log.Error("models.CategoryNameExists() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "分类创建失败")
+		return
+	}
+	if exists {
+		res.Error(c, res.CategoryExists, res.GetMsg(res.CategoryExists))
+		return
+	}
+
+	err = models.CategoryParentCheck(req.ParentID)
+	if err != nil {
+		global.Log.Error("models.CategoryParentCheck() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+
 	err = (&models.CategoryModel{
-		Name: req.Name,
+		Name:     req.Name,
+		ParentID: req.ParentID,
 	}).Create()
 	if err != nil {
 		global.Log.Error("category.Create() failed", zap.String("error", err.Error()))
@@ -1,9 +1,12 @@
 ﻿package category
 
 import (
+	"errors"
+
 	"blog/global"
 	"blog/models"
 	"blog/models/res"
+	"blog/service/sitemap_ser"
 	"blog/utils"
 
 	"github.com/gin-gonic/gin"
@@ -11,31 +14,81 @@ import (
 	"go.uber.org/zap"
 )
 
+type CategoryDeleteRequest struct {
+	TargetID uint `form:"target_id" validate:"omitempty,gt=0"` // 分类下有文章时，文章要移动到的分类
+}
+
+// CategoryDelete 删除分类，分类下有文章时需要指定目标分类，否则拒绝删除
 func (cg *Category) CategoryDelete(c *gin.Context) {
-	var req models.IDRequest
-	if err := c.ShouldBindUri(&req); err != nil {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
 		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
 		res.Error(c, res.InvalidParameter, "请求参数格式错误")
 		return
 	}
+	var req CategoryDeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
 
-	err := utils.Validate(req)
+	err := utils.Validate(uri)
+	if err == nil {
+		err = utils.Validate(req)
+	}
 	if err != nil {
 		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
 		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
 		return
 	}
 
-	err = (&models.CategoryModel{
-		MODEL: models.MODEL{
-			ID: req.ID,
-		},
-	}).Delete()
+	if req.TargetID != 0 {
+		source, target, ok := categoryPair(c, uri.ID, req.TargetID)
+		if !ok {
+			return
+		}
+		err := models.CategoryMerge(source, target)
+		if errors.Is(err, models.ErrCategoryCycle) {
+			res.Error(c, res.InvalidParameter, err.Error())
+			return
+		}
+		if err != nil {
+			global.Log.Error("models.CategoryMerge() failed", zap.String("error", err.Error()))
+			res.Error(c, res.ServerError, "分类删除失败")
+			return
+		}
+		sitemap_ser.MarkDirty()
+		global.Log.Info("分类删除成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+		res.Success(c, nil)
+		return
+	}
+
+	category, err := models.CategoryGet(uri.ID)
 	if err != nil {
+		global.Log.Error("models.CategoryGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "分类不存在")
+		return
+	}
+
+	// 包含草稿等所有状态的文章
+	counts, err := models.NewArticleService().TermCounts("category", []string{category.Name}, false)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().TermCounts() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "分类删除失败")
+		return
+	}
+	if counts[category.Name] > 0 {
+		res.ErrorWithData(c, res.CategoryInUse, res.GetMsg(res.CategoryInUse), gin.H{"article_count": counts[category.Name]})
+		return
+	}
+
+	if err := category.Delete(); err != nil {
 		global.Log.Error("category.Delete() failed", zap.String("error", err.Error()))
 		res.Error(c, res.ServerError, "分类删除失败")
 		return
 	}
+	sitemap_ser.MarkDirty()
 	global.Log.Info("分类删除成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
 	res.Success(c, nil)
 }
@@ -12,6 +12,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// CategoryListItem 分类列表项，附带已发布的文章数
+type CategoryListItem struct {
+	models.CategoryModel
+	ArticleCount int64 `json:"article_count"`
+}
+
 func (cg *Category) CategoryList(c *gin.Context) {
 	var req models.PageInfo
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -29,12 +35,27 @@ func (cg *Category) CategoryList(c *gin.Context) {
 
 	list, count, err := search_ser.ComList(models.CategoryModel{}, search_ser.Option{
 		PageInfo: req,
+		OrderBy:  "sort asc, id asc",
 	})
 	if err != nil {
 		global.Log.Error("search.ComList() failed", zap.String("error", err.Error()))
 		res.Error(c, res.ServerError, "加载失败")
 		return
 	}
+
+	names := make([]string, 0, len(list))
+	for _, category := range list {
+		names = append(names, category.Name)
+	}
+	counts, err := models.NewArticleService().TermCounts("category", names, true)
+	if err != nil {
+		// 统计失败不影响分类列表
+		global.Log.Error("models.NewArticleService().TermCounts() failed", zap.String("error", err.Error()))
+	}
+	items := make([]CategoryListItem, 0, len(list))
+	for _, category := range list {
+		items = append(items, CategoryListItem{CategoryModel: category, ArticleCount: counts[category.Name]})
+	}
 	global.Log.Info("分类列表成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
-	res.SuccessWithPage(c, list, count, req.Page, req.PageSize)
+	res.SuccessWithPage(c, items, count, req.Page, req.PageSize)
 }
@@ -0,0 +1,82 @@
+package category
+
+import (
+	"errors"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/sitemap_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type CategoryMergeRequest struct {
+	TargetID uint `json:"target_id" form:"target_id" validate:"required,gt=0"`
+}
+
+// CategoryMerge 将分类合并到目标分类，文章移动到目标分类后删除原分类
+func (cg *Category) CategoryMerge(c *gin.Context) {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req CategoryMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	source, target, ok := categoryPair(c, uri.ID, req.TargetID)
+	if !ok {
+		return
+	}
+
+	err = models.CategoryMerge(source, target)
+	if errors.Is(err, models.ErrCategoryCycle) {
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		global.Log.Error("models.CategoryMerge() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "分类合并失败")
+		return
+	}
+	sitemap_ser.MarkDirty()
+	global.Log.Info("分类合并成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, target)
+}
+
+// categoryPair 获取合并的源分类和目标分类，失败时直接写入错误响应
+func categoryPair(c *gin.Context, sourceID, targetID uint) (*models.CategoryModel, *models.CategoryModel, bool) {
+	if sourceID == targetID {
+		res.Error(c, res.InvalidParameter, "不能合并到分类自身")
+		return nil, nil, false
+	}
+	source, err := models.CategoryGet(sourceID)
+	if err != nil {
+		global.Log.Error("models.CategoryGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "分类不存在")
+		return nil, nil, false
+	}
+	target, err := models.CategoryGet(targetID)
+	if err != nil {
+		global.Log.Error("models.CategoryGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "目标分类不存在")
+		return nil, nil, false
+	}
+	return source, target, true
+}
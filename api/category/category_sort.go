@@ -0,0 +1,41 @@
+package category
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type CategorySortRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1,dive,gt=0"`
+}
+
+// CategorySort 按给定的id顺序重新排列分类
+func (cg *Category) CategorySort(c *gin.Context) {
+	var req CategorySortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	if err := models.CategorySort(req.IDs); err != nil {
+		global.Log.Error("models.CategorySort() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "分类排序失败")
+		return
+	}
+	global.Log.Info("分类排序成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, nil)
+}
@@ -0,0 +1,22 @@
+package category
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CategoryTree 分类树，子分类放在父分类的 children 中
+func (cg *Category) CategoryTree(c *gin.Context) {
+	tree, err := models.CategoryTree()
+	if err != nil {
+		global.Log.Error("models.CategoryTree() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取分类树失败")
+		return
+	}
+	global.Log.Info("分类树成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, tree)
+}
@@ -0,0 +1,84 @@
+package category
+
+import (
+	"errors"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/service/sitemap_ser"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type CategoryUpdateRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=10"`
+	Sort     int    `json:"sort" validate:"gte=0"`
+	ParentID *uint  `json:"parent_id" validate:"omitempty,gt=0"` // 为空时作为顶级分类
+}
+
+// CategoryUpdate 修改分类名称、排序和父分类，改名时同时修改所有文章中的分类
+func (cg *Category) CategoryUpdate(c *gin.Context) {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	category, err := models.CategoryGet(uri.ID)
+	if err != nil {
+		global.Log.Error("models.CategoryGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "分类不存在")
+		return
+	}
+
+	oldName := category.Name
+	if req.Name != oldName {
+		exists, err := models.CategoryNameExists(req.Name, category.ID)
+		if err != nil {
+			global.Log.Error("models.CategoryNameExists() failed", zap.String("error", err.Error()))
+			res.Error(c, res.ServerError, "分类修改失败")
+			return
+		}
+		if exists {
+			res.Error(c, res.CategoryExists, res.GetMsg(res.CategoryExists))
+			return
+		}
+	}
+
+	updated, err := category.Update(req.Name, req.Sort, req.ParentID)
+	if errors.Is(err, models.ErrParentCategoryNotExist) || errors.Is(err, models.ErrCategoryCycle) {
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		global.Log.Error("category.Update() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "分类修改失败")
+		return
+	}
+
+	if req.Name != oldName {
+		global.Log.Info("修改文章分类成功", zap.String("from", oldName), zap.String("to", req.Name), zap.Int64("articles", updated))
+		sitemap_ser.MarkDirty()
+	}
+
+	global.Log.Info("分类修改成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, category)
+}
@@ -6,10 +6,14 @@ import (
 	"blog/api/chat"
 	"blog/api/comment"
 	"blog/api/data"
+	"blog/api/feed"
 	"blog/api/friendlink"
 	"blog/api/image"
 	"blog/api/log"
+	"blog/api/series"
+	"blog/api/sitemap"
 	"blog/api/system"
+	"blog/api/tag"
 	"blog/api/user"
 	"blog/api/visit"
 )
@@ -26,6 +30,10 @@ type AppGroup struct {
 	VisitApi      visit.Visit
 	LogApi        log.Log
 	ChatApi       chat.Chat
+	FeedApi       feed.Feed
+	SitemapApi    sitemap.Sitemap
+	TagApi        tag.Tag
+	SeriesApi     series.Series
 }
 
 var AppGroupApp = new(AppGroup)
@@ -0,0 +1,51 @@
+package feed
+
+import (
+	"blog/service/feed_ser"
+	"blog/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Feed struct {
+}
+
+type FeedRequest struct {
+	Category string `form:"category"`
+}
+
+// selfURL 订阅源自身的完整地址
+func selfURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.RequestURI()
+}
+
+// writeFeed 输出订阅源，内容未变化时返回 304，避免阅读器重复下载
+func writeFeed(c *gin.Context, feed *feed_ser.Feed, contentType string) {
+	etag := `"` + utils.Md5(feed.XML) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=600")
+	if !feed.LastModified.IsZero() {
+		c.Header("Last-Modified", feed.LastModified.Format(http.TimeFormat))
+	}
+
+	// If-None-Match 优先于 If-Modified-Since
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if match == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := time.Parse(http.TimeFormat, c.GetHeader("If-Modified-Since")); err == nil {
+		if !feed.LastModified.IsZero() && !feed.LastModified.After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, contentType, feed.XML)
+}
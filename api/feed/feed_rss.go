@@ -0,0 +1,29 @@
+package feed
+
+import (
+	"blog/global"
+	"blog/models/res"
+	"blog/service/feed_ser"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FeedRSS RSS 2.0 订阅源，可通过 category 参数订阅单个分类
+func (f *Feed) FeedRSS(c *gin.Context) {
+	var req FeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	feed, err := feed_ser.BuildRSS(req.Category, selfURL(c))
+	if err != nil {
+		global.Log.Error("feed_ser.BuildRSS() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "生成订阅源失败")
+		return
+	}
+
+	writeFeed(c, feed, "application/rss+xml; charset=utf-8")
+}
@@ -0,0 +1,3 @@
+package series
+
+type Series struct{}
@@ -0,0 +1,64 @@
+package series
+
+import (
+	"errors"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type SeriesArticlesRequest struct {
+	ArticleIDs []string `json:"article_ids" validate:"unique,dive,required"`
+}
+
+// SeriesArticles 设置系列中的文章及顺序，用于添加、移除和重新排序
+func (s *Series) SeriesArticles(c *gin.Context) {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req SeriesArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	series, err := models.SeriesGet(uri.ID)
+	if err != nil {
+		global.Log.Error("models.SeriesGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "系列不存在")
+		return
+	}
+
+	if req.ArticleIDs == nil {
+		req.ArticleIDs = []string{}
+	}
+	err = series.SeriesArticlesSet(req.ArticleIDs)
+	if errors.Is(err, models.ErrSeriesArticleNotExist) {
+		res.Error(c, res.InvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		global.Log.Error("series.SeriesArticlesSet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "设置系列文章失败")
+		return
+	}
+	global.Log.Info("系列文章设置成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, series)
+}
@@ -0,0 +1,67 @@
+package series
+
+import (
+	"errors"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type SeriesRequest struct {
+	Title    string `json:"title" validate:"required,min=1,max=50"`
+	Abstract string `json:"abstract" validate:"max=200"`
+}
+
+type SeriesCreateRequest struct {
+	SeriesRequest
+	ArticleIDs []string `json:"article_ids" validate:"omitempty,unique,dive,required"`
+}
+
+func (s *Series) SeriesCreate(c *gin.Context) {
+	var req SeriesCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	series := &models.SeriesModel{
+		Title:      req.Title,
+		Abstract:   req.Abstract,
+		ArticleIDs: []string{},
+	}
+	if err := series.Create(); err != nil {
+		global.Log.Error("series.Create() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "系列创建失败")
+		return
+	}
+
+	if len(req.ArticleIDs) > 0 {
+		err = series.SeriesArticlesSet(req.ArticleIDs)
+		if errors.Is(err, models.ErrSeriesArticleNotExist) {
+			res.Error(c, res.InvalidParameter, err.Error())
+			return
+		}
+		if err != nil {
+			global.Log.Error("series.SeriesArticlesSet() failed", zap.String("error", err.Error()))
+			res.Error(c, res.ServerError, "设置系列文章失败")
+			return
+		}
+	}
+
+	global.Log.Info("系列创建成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, series)
+}
@@ -0,0 +1,44 @@
+package series
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// SeriesDelete 删除系列，系列中的文章本身不会被删除
+func (s *Series) SeriesDelete(c *gin.Context) {
+	var req models.IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	series, err := models.SeriesGet(req.ID)
+	if err != nil {
+		global.Log.Error("models.SeriesGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "系列不存在")
+		return
+	}
+
+	if err := series.Delete(); err != nil {
+		global.Log.Error("series.Delete() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "系列删除失败")
+		return
+	}
+	global.Log.Info("系列删除成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, nil)
+}
@@ -0,0 +1,50 @@
+package series
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// SeriesDetailResponse 系列详情，附带按顺序排列的已发布文章
+type SeriesDetailResponse struct {
+	*models.SeriesModel
+	Articles []models.Article `json:"articles"`
+}
+
+func (s *Series) SeriesDetail(c *gin.Context) {
+	var req models.IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	series, err := models.SeriesGet(req.ID)
+	if err != nil {
+		global.Log.Error("models.SeriesGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "系列不存在")
+		return
+	}
+
+	articles, err := models.NewArticleService().ArticleListByIDs(series.ArticleIDs)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleListByIDs() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取系列文章失败")
+		return
+	}
+	global.Log.Info("系列详情成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, SeriesDetailResponse{SeriesModel: series, Articles: articles})
+}
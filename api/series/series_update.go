@@ -0,0 +1,49 @@
+package series
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+func (s *Series) SeriesUpdate(c *gin.Context) {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	series, err := models.SeriesGet(uri.ID)
+	if err != nil {
+		global.Log.Error("models.SeriesGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "系列不存在")
+		return
+	}
+
+	if err := series.Update(req.Title, req.Abstract); err != nil {
+		global.Log.Error("series.Update() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "系列修改失败")
+		return
+	}
+	global.Log.Info("系列修改成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, series)
+}
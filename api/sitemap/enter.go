@@ -0,0 +1,4 @@
+package sitemap
+
+type Sitemap struct {
+}
@@ -0,0 +1,35 @@
+package sitemap
+
+import (
+	"blog/service/sitemap_ser"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapIndex sitemap 入口文件
+func (s *Sitemap) SitemapIndex(c *gin.Context) {
+	writeSitemap(c, sitemap_ser.IndexName)
+}
+
+// SitemapPart 文章数量超过单个文件上限时拆分出的 sitemap 文件
+func (s *Sitemap) SitemapPart(c *gin.Context) {
+	writeSitemap(c, c.Param("name"))
+}
+
+func writeSitemap(c *gin.Context, name string) {
+	data, generatedAt, ok := sitemap_ser.Get(name)
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	generatedAt = generatedAt.UTC().Truncate(time.Second)
+	if since, err := time.Parse(http.TimeFormat, c.GetHeader("If-Modified-Since")); err == nil && !generatedAt.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header("Last-Modified", generatedAt.Format(http.TimeFormat))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
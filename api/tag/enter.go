@@ -0,0 +1,3 @@
+package tag
+
+type Tag struct{}
@@ -0,0 +1,45 @@
+package tag
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type TagCloudRequest struct {
+	Size int `form:"size" validate:"omitempty,gt=0,lte=200"`
+}
+
+// TagCloud 标签云，返回已发布文章中使用的标签及文章数
+func (t *Tag) TagCloud(c *gin.Context) {
+	var req TagCloudRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		global.Log.Error("c.ShouldBindQuery() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+	if req.Size == 0 {
+		req.Size = 50
+	}
+
+	tags, err := models.NewArticleService().TagCloud(req.Size)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().TagCloud() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "获取标签云失败")
+		return
+	}
+	global.Log.Info("标签云成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, tags)
+}
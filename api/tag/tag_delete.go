@@ -0,0 +1,52 @@
+package tag
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// TagDelete 删除标签，同时从所有文章中移除该标签
+func (t *Tag) TagDelete(c *gin.Context) {
+	var req models.IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	tag, err := models.TagGet(req.ID)
+	if err != nil {
+		global.Log.Error("models.TagGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "标签不存在")
+		return
+	}
+
+	// 先修改文章，失败时标签仍然保留，可以重试
+	_, err = models.NewArticleService().ArticleTermReplace("tags", tag.Name, "")
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleTermReplace() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "移除文章标签失败")
+		return
+	}
+
+	if err := tag.Delete(); err != nil {
+		global.Log.Error("tag.Delete() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "标签删除失败")
+		return
+	}
+	global.Log.Info("标签删除成功", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, nil)
+}
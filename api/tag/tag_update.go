@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/models/res"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// TagUpdate 修改标签名，同时修改所有使用该标签的文章
+func (t *Tag) TagUpdate(c *gin.Context) {
+	var uri models.IDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		global.Log.Error("c.ShouldBindUri() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		global.Log.Error("c.ShouldBindJSON() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, "请求参数格式错误")
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		global.Log.Error("utils.Validate() failed", zap.String("error", err.Error()))
+		res.Error(c, res.InvalidParameter, utils.FormatValidationError(err.(validator.ValidationErrors)))
+		return
+	}
+
+	tag, err := models.TagGet(uri.ID)
+	if err != nil {
+		global.Log.Error("models.TagGet() failed", zap.String("error", err.Error()))
+		res.Error(c, res.NotFound, "标签不存在")
+		return
+	}
+	if tag.Name == req.Name {
+		res.Success(c, tag)
+		return
+	}
+
+	exist, err := models.TagNameExist(req.Name, tag.ID)
+	if err != nil {
+		global.Log.Error("models.TagNameExist() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "标签修改失败")
+		return
+	}
+	if exist {
+		res.Error(c, res.InvalidParameter, "标签名已存在")
+		return
+	}
+
+	// 先修改文章再修改标签名，文章修改失败时标签保持原名，重试会再次修改
+	updated, err := models.NewArticleService().ArticleTermReplace("tags", tag.Name, req.Name)
+	if err != nil {
+		global.Log.Error("models.NewArticleService().ArticleTermReplace() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "修改文章标签失败")
+		return
+	}
+	if err := tag.Rename(req.Name); err != nil {
+		global.Log.Error("tag.Rename() failed", zap.String("error", err.Error()))
+		res.Error(c, res.ServerError, "标签修改失败")
+		return
+	}
+	global.Log.Info("标签修改成功", zap.Int64("articles", updated), zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+	res.Success(c, tag)
+}
@@ -15,6 +15,7 @@ type Config struct {
 	Upload  Upload  `mapstructure:"upload"`
 	QQ      QQ      `mapstructure:"qq"`
 	TencentCos TencentCos `mapstructure:"tencent_cos"`
+	Site    Site    `mapstructure:"site"`
 }
 
 
@@ -1,6 +1,8 @@
 package config
 
 type Es struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host                  string `mapstructure:"host"`
+	Port                  int    `mapstructure:"port"`
+	HighlightFragmentSize int    `mapstructure:"highlight_fragment_size"` // 搜索高亮片段长度
+	HighlightFragments    int    `mapstructure:"highlight_fragments"`     // 搜索高亮片段数量
 }
@@ -0,0 +1,9 @@
+package config
+
+type Site struct {
+	Title       string `mapstructure:"title"`       // 站点名称
+	Description string `mapstructure:"description"` // 站点简介
+	URL         string `mapstructure:"url"`         // 站点前台地址，用于生成文章链接，如 https://example.com
+	Author      string `mapstructure:"author"`      // 站点作者
+	FeedSize    int    `mapstructure:"feed_size"`   // 订阅源包含的文章数量
+}
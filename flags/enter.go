@@ -74,6 +74,22 @@ func Newflags() {
 			Usage:   "创建索引",
 			Action:  EsIndexCreate,
 		},
+		{
+			Name:   "reindex",
+			Usage:  "按最新映射重建索引并切换别名",
+			Action: EsReindex,
+		},
+		{
+			Name:   "rollback-es",
+			Usage:  "将索引别名切换回旧索引",
+			Action: EsRollback,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "index",
+					Usage: "旧索引名称，如 article_index_v1",
+				},
+			},
+		},
 		{
 			Name:    "export-es",
 			Aliases: []string{"e-e"},
@@ -87,6 +103,19 @@ func Newflags() {
 				},
 			},
 		},
+		{
+			Name:    "export-site",
+			Aliases: []string{"e-s"},
+			Usage:   "导出所有已发布文章为静态站点",
+			Action:  SiteExport,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "path",
+					Usage: "输出目录",
+					Value: "site_export",
+				},
+			},
+		},
 		{
 			Name:    "import-es",
 			Aliases: []string{"i-e"},
@@ -98,6 +127,54 @@ func Newflags() {
 				},
 			},
 		},
+		{
+			Name:    "import-markdown",
+			Aliases: []string{"i-md"},
+			Usage:   "从 Hugo/Hexo 的 Markdown 文章目录导入文章",
+			Action:  MarkdownImport,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "path",
+					Usage:    "文章目录，如 content/posts 或 source/_posts",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "static",
+					Usage: "以 / 开头的图片所在的静态资源目录，默认与文章目录相同",
+				},
+				&cli.UintFlag{
+					Name:  "user_id",
+					Usage: "文章作者id，默认使用第一个管理员",
+				},
+				&cli.StringFlag{
+					Name:  "category",
+					Usage: "没有分类的文章使用的默认分类",
+					Value: "未分类",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "只列出将要导入的文章和图片，不实际创建",
+				},
+			},
+		},
+		{
+			Name:    "import-wordpress",
+			Aliases: []string{"i-wp"},
+			Usage:   "从 WordPress 导出的 WXR 文件导入文章、页面和评论",
+			Action:  WordpressImport,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "path",
+					Usage:    "WXR 文件路径",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "category",
+					Usage: "没有分类的文章和页面使用的默认分类",
+					Value: "未分类",
+				},
+			},
+		},
 	}
 	if len(os.Args) > 1 {
 		err := app.Run(os.Args)
@@ -17,6 +17,9 @@ func DB(c *cli.Context) (err error) {
 			&models.FriendLinkModel{},
 			&models.VisitModel{},
 			&models.LogModel{},
+			&models.ArticleRevisionModel{},
+			&models.TagModel{},
+			&models.SeriesModel{},
 		)
 	if err != nil {
 		global.Log.Error("生成数据库表结构失败", zap.String("error", err.Error()))
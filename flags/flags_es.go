@@ -2,6 +2,7 @@ package flags
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"blog/global"
 	"blog/models"
@@ -30,3 +31,28 @@ func EsIndexCreate(c *cli.Context) (err error) {
 	return nil
 
 }
+
+// EsReindex 按最新映射重建索引，数据迁移完成后切换别名
+func EsReindex(c *cli.Context) (err error) {
+	index, err := models.NewArticleService().IndexReindex()
+	if err != nil {
+		global.Log.Error("重建索引失败", zap.String("error", err.Error()))
+		return err
+	}
+	global.Log.Infof("重建索引成功,当前索引:%s", index)
+	return nil
+}
+
+// EsRollback 将索引别名切换回指定的旧索引
+func EsRollback(c *cli.Context) (err error) {
+	index := c.String("index")
+	if index == "" {
+		return fmt.Errorf("索引名不能为空")
+	}
+	err = models.NewArticleService().IndexRollback(index)
+	if err != nil {
+		global.Log.Error("回滚索引失败", zap.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package flags
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"blog/global"
+	"blog/models"
+	"blog/utils"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+)
+
+// 自动生成摘要时截取的字数
+const importAbstractLength = 100
+
+// Hexo 和 WordPress 共用的摘要分隔符
+var moreRegexp = regexp.MustCompile(`<!--\s*more\s*-->`)
+
+// articleImport 保存导入的文章：补全分类、标签和封面，渲染正文、生成 slug 后写入索引。
+// oldSlugs 为原站点使用的链接，记入 OldSlugs 后访问旧链接会重定向到新文章
+func articleImport(articleService *models.ArticleService, article *models.Article, oldSlugs ...string) error {
+	// 没有封面时与接口创建一致，随机使用一张已上传的图片
+	if article.CoverURL == "" {
+		var image models.ImageModel
+		if err := global.DB.Order("RAND()").Take(&image).Error; err == nil {
+			article.CoverID, article.CoverURL = image.ID, image.Path
+		}
+	}
+	if err := models.CategoryEnsure(article.Category); err != nil {
+		return fmt.Errorf("保存分类失败: %w", err)
+	}
+	if err := models.TagEnsure(article.Tags); err != nil {
+		return fmt.Errorf("保存标签失败: %w", err)
+	}
+	if err := article.Render(); err != nil {
+		return fmt.Errorf("渲染文章内容失败: %w", err)
+	}
+	if err := articleService.ArticleSlugAssign(article); err != nil {
+		return fmt.Errorf("生成文章链接失败: %w", err)
+	}
+	for _, slug := range oldSlugs {
+		if slug != "" && slug != article.Slug && !slices.Contains(article.OldSlugs, slug) {
+			article.OldSlugs = append(article.OldSlugs, slug)
+		}
+	}
+	if err := articleService.ArticleCreate(article); err != nil {
+		return err
+	}
+	if err := models.RevisionCreate(article, article.UserID); err != nil {
+		global.Log.Error("models.RevisionCreate() failed", zap.String("error", err.Error()))
+	}
+	return nil
+}
+
+// abstractExtract 从正文生成摘要，优先使用 <!-- more --> 之前的内容
+func abstractExtract(content string) string {
+	if loc := moreRegexp.FindStringIndex(content); loc != nil {
+		content = content[:loc[0]]
+	}
+	html, err := utils.ConvertMarkdownToHTML(content)
+	if err != nil {
+		return ""
+	}
+	return textTruncate(html, importAbstractLength)
+}
+
+// textTruncate 提取 HTML 中的纯文本并截取前 n 个字
+func textTruncate(html string, n int) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return ""
+	}
+	text := []rune(strings.Join(strings.Fields(doc.Text()), " "))
+	if len(text) > n {
+		text = text[:n]
+	}
+	return string(text)
+}
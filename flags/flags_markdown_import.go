@@ -0,0 +1,488 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/utils"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ![alt](path "title")
+	markdownImageRegexp = regexp.MustCompile(`(!\[[^\]]*\]\(\s*<?)([^)\s>]+)(>?(?:\s+"[^"]*")?\s*\))`)
+	// <img src="path">
+	htmlImageRegexp = regexp.MustCompile(`(<img\s[^>]*?src=["'])([^"']+)(["'])`)
+	// Hexo 资源文件夹中的图片：{% asset_img name.png 标题 %}
+	assetImageRegexp = regexp.MustCompile(`\{%\s*asset_img\s+(\S+)\s*(.*?)\s*%\}`)
+
+	// 前置元数据中常见的时间格式
+	frontMatterTimeLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"2006/01/02 15:04:05",
+		"2006/01/02",
+	}
+)
+
+// markdownPost 从 Markdown 文件解析出的文章
+type markdownPost struct {
+	Path       string
+	Title      string
+	Slug       string
+	Abstract   string
+	Date       time.Time
+	Updated    time.Time
+	Categories []string
+	Tags       []string
+	Cover      string
+	Draft      bool
+	Content    string
+}
+
+// markdownImporter 导入过程中共享的状态
+type markdownImporter struct {
+	root            string
+	static          string
+	defaultCategory string
+	dryRun          bool
+	user            models.UserModel
+	articleService  *models.ArticleService
+	images          map[string]*models.ImageModel // 本地路径 -> 已上传的图片
+}
+
+// MarkdownImport 从 Hugo/Hexo 的文章目录导入 Markdown 文章
+func MarkdownImport(c *cli.Context) error {
+	root := c.String("path")
+	info, err := os.Stat(root)
+	if err != nil {
+		global.Log.Error("读取目录失败", zap.String("error", err.Error()))
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s 不是目录", root)
+	}
+
+	importer := &markdownImporter{
+		root:            root,
+		static:          c.String("static"),
+		defaultCategory: c.String("category"),
+		dryRun:          c.Bool("dry-run"),
+		articleService:  models.NewArticleService(),
+		images:          make(map[string]*models.ImageModel),
+	}
+	if importer.static == "" {
+		importer.static = root
+	}
+	err = importer.userLoad(c.Uint("user_id"))
+	if err != nil {
+		global.Log.Error("获取文章作者失败", zap.String("error", err.Error()))
+		return err
+	}
+
+	var created, failed int
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Hugo 的 _index.md 是栏目页而不是文章
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") || d.Name() == "_index.md" {
+			return nil
+		}
+
+		post, err := markdownPostParse(path)
+		if err != nil {
+			failed++
+			global.Log.Error("解析文章失败", zap.String("path", path), zap.String("error", err.Error()))
+			return nil
+		}
+		if importer.dryRun {
+			importer.report(post)
+			created++
+			return nil
+		}
+		article, err := importer.create(post)
+		if err != nil {
+			failed++
+			global.Log.Error("导入文章失败", zap.String("path", path), zap.String("error", err.Error()))
+			return nil
+		}
+		created++
+		global.Log.Infof("导入文章 %s -> %s(%s)", path, article.Title, article.ID)
+		return nil
+	})
+	if err != nil {
+		global.Log.Error("遍历目录失败", zap.String("error", err.Error()))
+		return err
+	}
+
+	if importer.dryRun {
+		global.Log.Infof("试运行完成,将导入 %d 篇文章,解析失败 %d 篇", created, failed)
+		return nil
+	}
+	global.Log.Infof("Markdown导入完成,成功 %d 篇,失败 %d 篇", created, failed)
+	return nil
+}
+
+// userLoad 加载文章作者，未指定时使用第一个管理员
+func (im *markdownImporter) userLoad(userID uint) error {
+	if userID != 0 {
+		return global.DB.Take(&im.user, userID).Error
+	}
+	return global.DB.Where("role = ?", ctypes.RoleAdmin).Order("id").Take(&im.user).Error
+}
+
+// report 试运行时输出将要创建的文章和引用的本地图片
+func (im *markdownImporter) report(post *markdownPost) {
+	status := ctypes.StatusPublished
+	if post.Draft {
+		status = ctypes.StatusDraft
+	}
+	global.Log.Infof("[dry-run] %s: 标题=%q 日期=%s 状态=%s 分类=%v 标签=%v 封面=%q",
+		post.Path, post.Title, post.Date.Format(time.DateTime), status,
+		im.categories(post), post.Tags, post.Cover)
+
+	for _, link := range im.localImages(post) {
+		path := im.imagePath(post, link)
+		if _, err := os.Stat(path); err != nil {
+			global.Log.Warnf("[dry-run]   图片 %s 不存在(%s)", link, path)
+			continue
+		}
+		global.Log.Infof("[dry-run]   上传图片 %s", path)
+	}
+}
+
+// create 上传文章引用的本地图片并创建文章
+func (im *markdownImporter) create(post *markdownPost) (*models.Article, error) {
+	content := im.imagesRewrite(post)
+
+	var coverID uint
+	var coverURL string
+	switch {
+	case post.Cover != "" && isLocalLink(post.Cover):
+		image, err := im.upload(im.imagePath(post, post.Cover))
+		if err != nil {
+			global.Log.Warn("上传封面失败", zap.String("cover", post.Cover), zap.String("error", err.Error()))
+			break
+		}
+		coverID, coverURL = image.ID, image.Path
+	case post.Cover != "":
+		coverURL = post.Cover
+	}
+
+	id, err := utils.GenerateID()
+	if err != nil {
+		return nil, fmt.Errorf("生成ID失败: %w", err)
+	}
+	article := &models.Article{
+		ID:        strconv.FormatInt(id, 10),
+		CreatedAt: ctypes.MyTime(post.Date),
+		UpdatedAt: ctypes.MyTime(post.Updated),
+		Title:     post.Title,
+		Abstract:  post.Abstract,
+		Content:   content,
+		Category:  im.categories(post),
+		Tags:      post.Tags,
+		CoverID:   coverID,
+		CoverURL:  coverURL,
+		UserID:    im.user.ID,
+		UserName:  im.user.Nickname,
+		Status:    ctypes.StatusPublished,
+		PublishAt: ctypes.MyTime(post.Date),
+	}
+	if post.Draft {
+		article.Status = ctypes.StatusDraft
+	}
+
+	// 保留原站点的 slug，旧链接可以重定向到新文章
+	if err = articleImport(im.articleService, article, post.Slug); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// categories 文章分类，没有分类时使用默认分类
+func (im *markdownImporter) categories(post *markdownPost) []string {
+	if len(post.Categories) == 0 {
+		return []string{im.defaultCategory}
+	}
+	return post.Categories
+}
+
+// localImages 文章正文中引用的本地图片
+func (im *markdownImporter) localImages(post *markdownPost) []string {
+	var links []string
+	for _, re := range []*regexp.Regexp{markdownImageRegexp, htmlImageRegexp} {
+		for _, match := range re.FindAllStringSubmatch(post.Content, -1) {
+			if isLocalLink(match[2]) {
+				links = append(links, match[2])
+			}
+		}
+	}
+	for _, match := range assetImageRegexp.FindAllStringSubmatch(post.Content, -1) {
+		links = append(links, assetImageLink(post, match[1]))
+	}
+	return links
+}
+
+// imagesRewrite 上传正文中的本地图片并替换为上传后的地址，上传失败的图片保留原链接
+func (im *markdownImporter) imagesRewrite(post *markdownPost) string {
+	replace := func(link string) string {
+		image, err := im.upload(im.imagePath(post, link))
+		if err != nil {
+			global.Log.Warn("上传图片失败", zap.String("path", post.Path), zap.String("image", link), zap.String("error", err.Error()))
+			return link
+		}
+		return image.Path
+	}
+
+	content := assetImageRegexp.ReplaceAllStringFunc(post.Content, func(s string) string {
+		match := assetImageRegexp.FindStringSubmatch(s)
+		return fmt.Sprintf("![%s](%s)", match[2], replace(assetImageLink(post, match[1])))
+	})
+	for _, re := range []*regexp.Regexp{markdownImageRegexp, htmlImageRegexp} {
+		content = re.ReplaceAllStringFunc(content, func(s string) string {
+			match := re.FindStringSubmatch(s)
+			if !isLocalLink(match[2]) {
+				return s
+			}
+			return match[1] + replace(match[2]) + match[3]
+		})
+	}
+	return content
+}
+
+// imagePath 本地图片的文件路径，以 / 开头的相对于静态资源目录，其余相对于文章所在目录
+func (im *markdownImporter) imagePath(post *markdownPost, link string) string {
+	link, _, _ = strings.Cut(link, "?")
+	link, _, _ = strings.Cut(link, "#")
+	if unescaped, err := url.PathUnescape(link); err == nil {
+		link = unescaped
+	}
+	if strings.HasPrefix(link, "/") {
+		return filepath.Join(im.static, filepath.FromSlash(link))
+	}
+	return filepath.Join(filepath.Dir(post.Path), filepath.FromSlash(link))
+}
+
+// upload 通过图片上传流程保存本地图片，同一文件只上传一次
+func (im *markdownImporter) upload(path string) (*models.ImageModel, error) {
+	if image, ok := im.images[path]; ok {
+		return image, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// 本地保存时直接使用文件名，不同目录下的同名图片(如 cover.jpg)需要按内容命名以免互相覆盖
+	name := utils.Md5(data) + strings.ToLower(filepath.Ext(path))
+	resp := (&models.ImageModel{}).UploadBytes(name, data)
+	if !resp.IsSuccess {
+		return nil, errors.New(resp.Msg)
+	}
+
+	var image models.ImageModel
+	err = global.DB.Where("hash = ?", resp.Hash).Take(&image).Error
+	if err != nil {
+		return nil, err
+	}
+	im.images[path] = &image
+	return &image, nil
+}
+
+// assetImageLink Hexo 资源文件夹与文章同名，位于文章旁边
+func assetImageLink(post *markdownPost, name string) string {
+	folder := strings.TrimSuffix(filepath.Base(post.Path), filepath.Ext(post.Path))
+	return folder + "/" + name
+}
+
+// postName 文章文件名，Hugo 页面包(目录/index.md)使用目录名
+func postName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name == "index" {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return name
+}
+
+// isLocalLink 判断图片链接是否指向本地文件
+func isLocalLink(link string) bool {
+	lower := strings.ToLower(link)
+	for _, prefix := range []string{"http://", "https://", "//", "data:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+	return link != ""
+}
+
+// markdownPostParse 读取 Markdown 文件并解析 YAML(---) 或 TOML(+++) 前置元数据
+func markdownPostParse(path string) (*markdownPost, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimPrefix(string(data), "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	meta := map[string]any{}
+	if head, body, ok := frontMatterSplit(content, "---"); ok {
+		if err := yaml.Unmarshal([]byte(head), &meta); err != nil {
+			return nil, fmt.Errorf("解析 YAML 元数据失败: %w", err)
+		}
+		content = body
+	} else if head, body, ok := frontMatterSplit(content, "+++"); ok {
+		if err := toml.Unmarshal([]byte(head), &meta); err != nil {
+			return nil, fmt.Errorf("解析 TOML 元数据失败: %w", err)
+		}
+		content = body
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, utils.ErrEmptyContent
+	}
+
+	post := &markdownPost{
+		Path:       path,
+		Title:      metaString(meta, "title"),
+		Slug:       metaString(meta, "slug"),
+		Abstract:   metaString(meta, "description", "summary", "excerpt"),
+		Date:       metaTime(meta, "date"),
+		Updated:    metaTime(meta, "lastmod", "updated"),
+		Categories: metaStrings(meta, "categories", "category"),
+		Tags:       metaStrings(meta, "tags", "tag"),
+		Cover:      metaCover(meta),
+		Draft:      metaBool(meta, "draft") || meta["published"] == false,
+		Content:    content,
+	}
+	if post.Title == "" {
+		post.Title = postName(path)
+	}
+	// Hexo 的草稿放在 _drafts 目录中
+	if slices.Contains(strings.Split(filepath.ToSlash(filepath.Dir(path)), "/"), "_drafts") {
+		post.Draft = true
+	}
+	if post.Date.IsZero() {
+		post.Date = time.Now()
+	}
+	if post.Updated.IsZero() {
+		post.Updated = post.Date
+	}
+	if post.Abstract == "" {
+		post.Abstract = abstractExtract(content)
+	}
+	return post, nil
+}
+
+// frontMatterSplit 按分隔行拆分前置元数据和正文
+func frontMatterSplit(content, delim string) (head, body string, ok bool) {
+	if !strings.HasPrefix(content, delim+"\n") {
+		return "", content, false
+	}
+	rest := "\n" + content[len(delim)+1:]
+	i := strings.Index(rest, "\n"+delim+"\n")
+	if i < 0 {
+		if strings.HasSuffix(rest, "\n"+delim) {
+			return rest[:len(rest)-len(delim)-1], "", true
+		}
+		return "", content, false
+	}
+	return rest[:i], rest[i+len(delim)+2:], true
+}
+
+// metaString 依次查找多个字段，返回第一个非空的字符串值
+func metaString(meta map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := meta[key]; ok && value != nil {
+			if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// metaStrings 读取字符串列表，Hexo 的多级分类会被展开
+func metaStrings(meta map[string]any, keys ...string) []string {
+	var result []string
+	var collect func(value any)
+	collect = func(value any) {
+		switch v := value.(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				collect(item)
+			}
+		default:
+			s := strings.TrimSpace(fmt.Sprint(v))
+			if s != "" && !utils.InList(s, result) {
+				result = append(result, s)
+			}
+		}
+	}
+	for _, key := range keys {
+		collect(meta[key])
+	}
+	return result
+}
+
+// metaTime 解析时间字段，YAML/TOML 解析出的时间类型和常见的字符串格式都支持
+func metaTime(meta map[string]any, keys ...string) time.Time {
+	for _, key := range keys {
+		switch v := meta[key].(type) {
+		case nil:
+			continue
+		case time.Time:
+			return v
+		default:
+			s := strings.TrimSpace(fmt.Sprint(v))
+			for _, layout := range frontMatterTimeLayouts {
+				if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// metaBool 读取布尔字段，兼容字符串形式的 "true"
+func metaBool(meta map[string]any, key string) bool {
+	switch v := meta[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// metaCover 读取封面，兼容 Hugo 主题常用的 cover.image 写法
+func metaCover(meta map[string]any) string {
+	if cover, ok := meta["cover"].(map[string]any); ok {
+		return metaString(cover, "image")
+	}
+	return metaString(meta, "cover", "image", "thumbnail", "featured_image")
+}
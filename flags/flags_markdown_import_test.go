@@ -0,0 +1,80 @@
+package flags
+
+import "testing"
+
+func TestFrontMatterSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		delim    string
+		wantHead string
+		wantBody string
+		wantOK   bool
+	}{
+		{
+			name:     "YAML",
+			content:  "---\ntitle: 标题\n---\n正文\n",
+			delim:    "---",
+			wantHead: "\ntitle: 标题",
+			wantBody: "正文\n",
+			wantOK:   true,
+		},
+		{
+			name:     "TOML",
+			content:  "+++\ntitle = \"标题\"\n+++\n正文",
+			delim:    "+++",
+			wantHead: "\ntitle = \"标题\"",
+			wantBody: "正文",
+			wantOK:   true,
+		},
+		{
+			name:     "空的元数据",
+			content:  "---\n---\n正文",
+			delim:    "---",
+			wantHead: "",
+			wantBody: "正文",
+			wantOK:   true,
+		},
+		{
+			name:     "结束分隔行在末尾",
+			content:  "---\ntitle: 标题\n---",
+			delim:    "---",
+			wantHead: "\ntitle: 标题",
+			wantBody: "",
+			wantOK:   true,
+		},
+		{
+			name:     "正文中的分隔线",
+			content:  "---\ntitle: 标题\n---\n上文\n---\n下文",
+			delim:    "---",
+			wantHead: "\ntitle: 标题",
+			wantBody: "上文\n---\n下文",
+			wantOK:   true,
+		},
+		{
+			name:     "没有结束分隔行",
+			content:  "---\ntitle: 标题\n正文",
+			delim:    "---",
+			wantHead: "",
+			wantBody: "---\ntitle: 标题\n正文",
+			wantOK:   false,
+		},
+		{
+			name:     "没有元数据",
+			content:  "# 标题\n---\n正文",
+			delim:    "---",
+			wantHead: "",
+			wantBody: "# 标题\n---\n正文",
+			wantOK:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, body, ok := frontMatterSplit(tt.content, tt.delim)
+			if head != tt.wantHead || body != tt.wantBody || ok != tt.wantOK {
+				t.Errorf("frontMatterSplit() = (%q, %q, %v), want (%q, %q, %v)",
+					head, body, ok, tt.wantHead, tt.wantBody, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package flags
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"blog/config"
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/service/feed_ser"
+	"blog/service/sitemap_ser"
+	"blog/utils"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+// 静态页面模板，文章页路径与前台保持一致：/article/<id>/；
+// 分类名可能包含 / 等字符，分类页使用由分类名生成的 slug：/category/<slug>/
+const siteTemplateText = `
+{{define "head"}}<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="alternate" type="application/rss+xml" title="{{.Site.Title}}" href="/feed.xml">
+<style>
+body{max-width:760px;margin:0 auto;padding:0 1em;font-family:sans-serif;line-height:1.7;color:#222}
+a{color:#0969da;text-decoration:none}
+header{padding:1em 0;border-bottom:1px solid #eee}
+footer{padding:1em 0;border-top:1px solid #eee;color:#888;font-size:.9em}
+.meta{color:#888;font-size:.9em}
+pre{background:#f6f8fa;padding:1em;overflow:auto}
+img{max-width:100%}
+.comments ul{list-style:none;padding-left:1.5em;border-left:2px solid #eee}
+</style>
+</head>
+<body>
+<header><a href="/"><strong>{{.Site.Title}}</strong></a>{{with .Site.Description}} <span class="meta">{{.}}</span>{{end}}</header>
+<main>
+{{end}}
+
+{{define "foot"}}</main>
+<footer>静态备份生成于 {{.GeneratedAt}} · <a href="/feed.xml">RSS</a> · <a href="/sitemap.xml">Sitemap</a></footer>
+</body>
+</html>
+{{end}}
+
+{{define "list"}}<ul>
+{{range .}}<li><a href="/article/{{.ID}}/">{{.Title}}</a> <span class="meta">{{date .CreatedAt}}</span><br><span class="meta">{{.Abstract}}</span></li>
+{{end}}</ul>{{end}}
+
+{{define "comments"}}<ul>
+{{range .}}<li><p class="meta">{{.User.Nickname}} · {{date .CreatedAt}}</p>{{html .Content}}{{with .SubComments}}{{template "comments" .}}{{end}}</li>
+{{end}}</ul>{{end}}
+
+{{define "index"}}{{template "head" .}}
+{{with .Categories}}<nav>{{range .}}<a href="{{.URL}}">{{.Name}}</a> {{end}}</nav>{{end}}
+{{template "list" .Articles}}
+{{template "foot" .}}{{end}}
+
+{{define "category"}}{{template "head" .}}
+<h1>{{.Heading}}</h1>
+{{template "list" .Articles}}
+{{template "foot" .}}{{end}}
+
+{{define "article"}}{{template "head" .}}
+<article>
+<h1>{{.Article.Title}}</h1>
+<p class="meta">{{.Article.UserName}} · {{date .Article.CreatedAt}}{{range .Categories}} · <a href="{{.URL}}">{{.Name}}</a>{{end}}{{range .Article.Tags}} #{{.}}{{end}}</p>
+{{html .Article.ContentHTML}}
+</article>
+{{with .Comments}}<section class="comments"><h2>评论</h2>{{template "comments" .}}</section>{{end}}
+{{template "foot" .}}{{end}}
+`
+
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"date": func(t ctypes.MyTime) string { return time.Time(t).Format(time.DateOnly) },
+	// 正文和评论在保存时已经过清理
+	"html": func(s string) template.HTML { return template.HTML(s) },
+}).Parse(siteTemplateText))
+
+// siteLink 页面中的链接
+type siteLink struct {
+	Name string
+	URL  string
+}
+
+// sitePage 页面模板数据
+type sitePage struct {
+	Site        config.Site
+	GeneratedAt string
+	Title       string
+	Heading     string
+	Categories  []siteLink
+	Articles    []models.Article
+	Article     *models.Article
+	Comments    []*models.CommentModel
+}
+
+// siteExporter 静态站点导出过程中共享的状态
+type siteExporter struct {
+	out         string
+	generatedAt string
+	articles    []models.Article            // 首页列表，不含正文
+	byCategory  map[string][]models.Article // 分类 -> 文章列表
+	categories  []string                    // 分类表中的分类和文章上已不在分类表中的分类
+	dirs        map[string]string           // 分类 -> 分类页目录
+	usedDirs    map[string]bool
+	images      int
+	missing     int
+}
+
+// SiteExport 将所有已发布的文章导出为可以直接部署到静态托管的 HTML 站点
+func SiteExport(c *cli.Context) error {
+	exporter := &siteExporter{
+		out:         c.String("path"),
+		generatedAt: time.Now().Format(time.DateTime),
+		byCategory:  make(map[string][]models.Article),
+		dirs:        make(map[string]string),
+		usedDirs:    make(map[string]bool),
+	}
+
+	// 先按后台排序为分类分配目录，同一份数据每次导出的分类页路径不变
+	err := exporter.categoriesLoad()
+	if err != nil {
+		global.Log.Error("获取分类失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = exporter.articlesWrite()
+	if err != nil {
+		global.Log.Error("导出文章失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = exporter.indexWrite()
+	if err != nil {
+		global.Log.Error("导出首页和分类页失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = exporter.feedWrite()
+	if err != nil {
+		global.Log.Error("导出RSS失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = exporter.sitemapWrite()
+	if err != nil {
+		global.Log.Error("导出sitemap失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = exporter.imagesCopy()
+	if err != nil {
+		global.Log.Error("复制图片失败", zap.String("error", err.Error()))
+		return err
+	}
+
+	global.Log.Infof("静态站点导出成功,目录:%s,文章 %d 篇,分类 %d 个,图片 %d 张,缺失图片 %d 张",
+		exporter.out, len(exporter.articles), len(exporter.byCategory), exporter.images, exporter.missing)
+	return nil
+}
+
+// categoriesLoad 读取分类表，分类按后台排序
+func (e *siteExporter) categoriesLoad() error {
+	var categories []models.CategoryModel
+	err := global.DB.Order("sort asc, id asc").Find(&categories).Error
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		e.categoryLink(category.Name)
+	}
+	return nil
+}
+
+// categoryLink 分类页链接。目录名使用分类名的 slug，不会包含 / 或 ..，重复时加上序号
+func (e *siteExporter) categoryLink(name string) siteLink {
+	dir, ok := e.dirs[name]
+	if !ok {
+		base := utils.Slugify(name)
+		if base == "" {
+			base = "category"
+		}
+		dir = base
+		for i := 2; e.usedDirs[dir]; i++ {
+			dir = fmt.Sprintf("%s-%d", base, i)
+		}
+		e.dirs[name] = dir
+		e.usedDirs[dir] = true
+		e.categories = append(e.categories, name)
+	}
+	return siteLink{Name: name, URL: "/category/" + dir + "/"}
+}
+
+// articlesWrite 遍历全部已发布文章，逐篇生成文章页，列表按创建时间倒序排列
+func (e *siteExporter) articlesWrite() error {
+	err := models.NewArticleService().ArticleScan(nil, func(articles []models.Article) error {
+		for i := range articles {
+			article := &articles[i]
+			if err := e.articleWrite(article); err != nil {
+				return err
+			}
+
+			// 列表页只需要摘要信息
+			article.Content, article.ContentHTML, article.Toc = "", "", nil
+			e.articles = append(e.articles, *article)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(e.articles, func(a, b models.Article) int {
+		if c := time.Time(b.CreatedAt).Compare(time.Time(a.CreatedAt)); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	for _, article := range e.articles {
+		for _, category := range article.Category {
+			e.byCategory[category] = append(e.byCategory[category], article)
+		}
+	}
+	return nil
+}
+
+// articleWrite 生成单篇文章页，包含评论树
+func (e *siteExporter) articleWrite(article *models.Article) error {
+	// 早期保存的文章没有渲染结果
+	if article.ContentHTML == "" {
+		if err := article.Render(); err != nil {
+			global.Log.Warn("渲染文章失败", zap.String("id", article.ID), zap.String("error", err.Error()))
+		}
+	}
+	comments, err := models.GetArticleCommentsWithTree(article.ID)
+	if err != nil {
+		global.Log.Warn("获取评论失败", zap.String("id", article.ID), zap.String("error", err.Error()))
+	}
+
+	page := e.page(article.Title)
+	page.Article = article
+	page.Comments = comments
+	for _, category := range article.Category {
+		page.Categories = append(page.Categories, e.categoryLink(category))
+	}
+	return e.render(filepath.Join("article", article.ID, "index.html"), "article", page)
+}
+
+// indexWrite 生成首页和每个分类的文章列表页，分类按后台排序
+func (e *siteExporter) indexWrite() error {
+	var links []siteLink
+	for _, name := range e.categories {
+		links = append(links, e.categoryLink(name))
+	}
+
+	page := e.page("")
+	page.Title = global.Config.Site.Title
+	page.Categories = links
+	page.Articles = e.articles
+	err := e.render("index.html", "index", page)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		page := e.page(link.Name)
+		page.Heading = link.Name
+		page.Articles = e.byCategory[link.Name]
+		err = e.render(filepath.Join("category", e.dirs[link.Name], "index.html"), "category", page)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feedWrite 生成全站 RSS
+func (e *siteExporter) feedWrite() error {
+	site := strings.TrimRight(global.Config.Site.URL, "/")
+	feed, err := feed_ser.BuildRSS("", site+"/feed.xml")
+	if err != nil {
+		return err
+	}
+	return e.write("feed.xml", feed.XML)
+}
+
+// sitemapWrite 生成 sitemap，地址使用导出页面的实际路径，分片文件与线上一样放在 sitemaps 目录下
+func (e *siteExporter) sitemapWrite() error {
+	site := strings.TrimRight(global.Config.Site.URL, "/")
+	urls := []sitemap_ser.URL{{Loc: site + "/"}}
+	for _, name := range e.categories {
+		urls = append(urls, sitemap_ser.URL{Loc: site + e.categoryLink(name).URL})
+	}
+	for _, article := range e.articles {
+		urls = append(urls, sitemap_ser.URL{
+			Loc:     site + "/article/" + article.ID + "/",
+			LastMod: time.Time(article.UpdatedAt),
+		})
+	}
+
+	files, err := sitemap_ser.Build(site, urls)
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		path := name
+		if name != sitemap_ser.IndexName {
+			path = filepath.Join("sitemaps", name)
+		}
+		if err := e.write(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imagesCopy 复制本地存储的图片，保持与线上相同的访问路径
+func (e *siteExporter) imagesCopy() error {
+	var images []models.ImageModel
+	err := global.DB.Where("type = ?", models.LocalStorage).Find(&images).Error
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		src := strings.TrimPrefix(image.Path, "/")
+		err := e.copy(src, filepath.Join(e.out, filepath.FromSlash(src)))
+		if os.IsNotExist(err) {
+			e.missing++
+			global.Log.Warn("图片文件不存在", zap.String("path", image.Path))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		e.images++
+	}
+	return nil
+}
+
+// page 页面公共数据
+func (e *siteExporter) page(title string) *sitePage {
+	if title != "" {
+		title += " - " + global.Config.Site.Title
+	}
+	return &sitePage{
+		Site:        global.Config.Site,
+		GeneratedAt: e.generatedAt,
+		Title:       title,
+	}
+}
+
+// render 渲染模板并写入输出目录
+func (e *siteExporter) render(path, name string, data *sitePage) error {
+	var buf bytes.Buffer
+	if err := siteTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("渲染 %s 失败: %w", path, err)
+	}
+	return e.write(path, buf.Bytes())
+}
+
+// write 写入输出目录下的文件，自动创建上级目录
+func (e *siteExporter) write(path string, data []byte) error {
+	path = filepath.Join(e.out, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// copy 复制文件
+func (e *siteExporter) copy(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,558 @@
+package flags
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/global"
+	"blog/models"
+	"blog/models/ctypes"
+	"blog/utils"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WXR 的命名空间带有版本号(如 http://wordpress.org/export/1.2/)，除了 encoded 之外都只按本地名匹配
+type wxrFile struct {
+	Channel struct {
+		Authors    []wxrAuthor   `xml:"author"`
+		Categories []wxrCategory `xml:"category"`
+		Tags       []wxrTag      `xml:"tag"`
+		Items      []wxrItem     `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrAuthor struct {
+	ID          int    `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Parent   string `xml:"category_parent"`
+	Name     string `xml:"cat_name"`
+}
+
+type wxrTag struct {
+	Name string `xml:"tag_name"`
+}
+
+type wxrItem struct {
+	Title       string            `xml:"title"`
+	Creator     string            `xml:"creator"`
+	Encoded     []wxrEncoded      `xml:"encoded"` // content:encoded 为正文，excerpt:encoded 为摘要
+	PostDate    string            `xml:"post_date"`
+	PostDateGMT string            `xml:"post_date_gmt"`
+	ModifiedGMT string            `xml:"post_modified_gmt"`
+	PostName    string            `xml:"post_name"`
+	Status      string            `xml:"status"`
+	PostType    string            `xml:"post_type"`
+	Categories  []wxrItemCategory `xml:"category"`
+	Comments    []wxrComment      `xml:"comment"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrItemCategory struct {
+	Domain   string `xml:"domain,attr"` // category 或 post_tag
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID       int    `xml:"comment_id"`
+	Author   string `xml:"comment_author"`
+	Email    string `xml:"comment_author_email"`
+	Date     string `xml:"comment_date"`
+	DateGMT  string `xml:"comment_date_gmt"`
+	Content  string `xml:"comment_content"`
+	Approved string `xml:"comment_approved"`
+	Type     string `xml:"comment_type"`
+	Parent   int    `xml:"comment_parent"`
+	UserID   int    `xml:"comment_user_id"`
+}
+
+// wordpressReport 导入结果统计
+type wordpressReport struct {
+	Posts           int
+	Pages           int
+	Drafts          int
+	Skipped         int
+	Failed          int
+	Categories      int
+	Tags            int
+	UsersMatched    int
+	UsersCreated    int
+	Comments        int
+	CommentsSkipped int
+	CommentsFailed  int
+}
+
+// wordpressImporter 导入过程中共享的状态
+type wordpressImporter struct {
+	defaultCategory string
+	articleService  *models.ArticleService
+	authors         map[string]wxrAuthor         // 登录名 -> 作者
+	authorIDs       map[int]wxrAuthor            // WordPress 用户id -> 作者
+	users           map[string]*models.UserModel // 邮箱、登录名或昵称 -> 用户
+	categories      map[string]string            // 别名 -> 分类名称
+	report          wordpressReport
+}
+
+var (
+	// 旧版编辑器保存的正文中已经是块级元素的段落
+	wpBlockRegexp    = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|pre|blockquote|table|figure|hr|!--)[\s>/]`)
+	wpParagraphSplit = regexp.MustCompile(`\n\s*\n`)
+)
+
+// WordpressImport 从 WordPress 导出的 WXR 文件导入文章、页面、分类、标签、作者和评论
+func WordpressImport(c *cli.Context) error {
+	file, err := os.Open(c.String("path"))
+	if err != nil {
+		global.Log.Error("读取文件失败", zap.String("error", err.Error()))
+		return err
+	}
+	defer file.Close()
+
+	var wxr wxrFile
+	err = xml.NewDecoder(file).Decode(&wxr)
+	if err != nil {
+		global.Log.Error("解析WXR文件失败", zap.String("error", err.Error()))
+		return err
+	}
+
+	importer := &wordpressImporter{
+		defaultCategory: c.String("category"),
+		articleService:  models.NewArticleService(),
+		authors:         make(map[string]wxrAuthor),
+		authorIDs:       make(map[int]wxrAuthor),
+		users:           make(map[string]*models.UserModel),
+		categories:      make(map[string]string),
+	}
+	for _, author := range wxr.Channel.Authors {
+		importer.authors[author.Login] = author
+		importer.authorIDs[author.ID] = author
+	}
+
+	err = importer.categoriesImport(wxr.Channel.Categories)
+	if err != nil {
+		global.Log.Error("导入分类失败", zap.String("error", err.Error()))
+		return err
+	}
+	err = importer.tagsImport(wxr.Channel.Tags, wxr.Channel.Items)
+	if err != nil {
+		global.Log.Error("导入标签失败", zap.String("error", err.Error()))
+		return err
+	}
+
+	for _, item := range wxr.Channel.Items {
+		importer.itemImport(item)
+	}
+
+	r := importer.report
+	global.Log.Infof("WordPress导入完成: 文章 %d 篇, 页面 %d 篇, 其中草稿 %d 篇, 跳过 %d 条, 失败 %d 条",
+		r.Posts, r.Pages, r.Drafts, r.Skipped, r.Failed)
+	global.Log.Infof("分类新增 %d 个, 标签共 %d 个, 匹配已有用户 %d 个, 新建占位用户 %d 个",
+		r.Categories, r.Tags, r.UsersMatched, r.UsersCreated)
+	global.Log.Infof("评论导入 %d 条, 跳过未审核评论和引用通告 %d 条, 失败 %d 条",
+		r.Comments, r.CommentsSkipped, r.CommentsFailed)
+	return nil
+}
+
+// categoriesImport 按父子关系创建分类，已存在的同名分类直接复用
+func (im *wordpressImporter) categoriesImport(categories []wxrCategory) error {
+	byNicename := make(map[string]wxrCategory, len(categories))
+	for _, category := range categories {
+		byNicename[category.Nicename] = category
+	}
+
+	ids := make(map[string]uint)
+	var ensure func(nicename string, depth int) (uint, error)
+	ensure = func(nicename string, depth int) (uint, error) {
+		if id, ok := ids[nicename]; ok {
+			return id, nil
+		}
+		category, ok := byNicename[nicename]
+		if !ok || depth > len(categories) {
+			return 0, nil
+		}
+
+		var parentID *uint
+		if category.Parent != "" {
+			id, err := ensure(category.Parent, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			if id != 0 {
+				parentID = &id
+			}
+		}
+
+		var model models.CategoryModel
+		err := global.DB.Where("name = ?", category.Name).Take(&model).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			model = models.CategoryModel{Name: category.Name, ParentID: parentID}
+			err = model.Create()
+			im.report.Categories++
+		}
+		if err != nil {
+			return 0, err
+		}
+		ids[nicename] = model.ID
+		im.categories[nicename] = model.Name
+		return model.ID, nil
+	}
+
+	for _, category := range categories {
+		if _, err := ensure(category.Nicename, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagsImport 创建文件中声明的和文章上使用的全部标签
+func (im *wordpressImporter) tagsImport(tags []wxrTag, items []wxrItem) error {
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	for _, item := range items {
+		_, itemTags := im.itemTerms(item)
+		names = append(names, itemTags...)
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	im.report.Tags = len(names)
+	return models.TagEnsure(names)
+}
+
+// itemTerms 文章上的分类和标签
+func (im *wordpressImporter) itemTerms(item wxrItem) (categories, tags []string) {
+	for _, term := range item.Categories {
+		name := strings.TrimSpace(term.Name)
+		switch term.Domain {
+		case "category":
+			if mapped, ok := im.categories[term.Nicename]; ok {
+				name = mapped
+			}
+			if name != "" && !slices.Contains(categories, name) {
+				categories = append(categories, name)
+			}
+		case "post_tag":
+			if name != "" && !slices.Contains(tags, name) {
+				tags = append(tags, name)
+			}
+		}
+	}
+	return categories, tags
+}
+
+// itemImport 导入一篇文章或页面及其评论，附件、修订版本和回收站中的内容会被跳过
+func (im *wordpressImporter) itemImport(item wxrItem) {
+	if item.PostType != "post" && item.PostType != "page" {
+		im.report.Skipped++
+		return
+	}
+	status, publishAt, ok := wxrStatus(item)
+	if !ok {
+		im.report.Skipped++
+		return
+	}
+
+	article, comments, err := im.articleBuild(item, status, publishAt)
+	if err == nil {
+		slug, _ := url.PathUnescape(item.PostName)
+		err = articleImport(im.articleService, article, slug)
+	}
+	if err != nil {
+		im.report.Failed++
+		global.Log.Error("导入文章失败", zap.String("title", item.Title), zap.String("error", err.Error()))
+		return
+	}
+
+	if item.PostType == "page" {
+		im.report.Pages++
+	} else {
+		im.report.Posts++
+	}
+	if status == ctypes.StatusDraft {
+		im.report.Drafts++
+	}
+	im.commentsImport(article.ID, comments)
+	global.Log.Infof("导入文章 %s(%s)", article.Title, article.ID)
+}
+
+// articleBuild 根据 WXR 条目构造文章，同时返回需要导入的评论
+func (im *wordpressImporter) articleBuild(item wxrItem, status ctypes.ArticleStatus, publishAt time.Time) (*models.Article, []wxrComment, error) {
+	var content, excerpt string
+	for _, encoded := range item.Encoded {
+		switch {
+		case strings.Contains(encoded.XMLName.Space, "excerpt"):
+			excerpt = encoded.Value
+		case strings.Contains(encoded.XMLName.Space, "content"):
+			content = encoded.Value
+		}
+	}
+	markdown, err := utils.ConvertHTMLToMarkdown(wpautop(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := im.authorUser(item.Creator)
+	if err != nil {
+		return nil, nil, fmt.Errorf("匹配作者失败: %w", err)
+	}
+
+	id, err := utils.GenerateID()
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成ID失败: %w", err)
+	}
+
+	categories, tags := im.itemTerms(item)
+	if len(categories) == 0 {
+		categories = []string{im.defaultCategory}
+	}
+	abstract := textTruncate(excerpt, importAbstractLength)
+	if abstract == "" {
+		abstract = abstractExtract(markdown)
+	}
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		title = "未命名"
+	}
+
+	// 只导入审核通过的普通评论
+	var comments []wxrComment
+	for _, comment := range item.Comments {
+		if comment.Approved != "1" || (comment.Type != "" && comment.Type != "comment") || strings.TrimSpace(comment.Content) == "" {
+			im.report.CommentsSkipped++
+			continue
+		}
+		comments = append(comments, comment)
+	}
+
+	created := wxrTime(item.PostDateGMT, item.PostDate)
+	if created.IsZero() {
+		created = time.Now()
+	}
+	updated := wxrTime(item.ModifiedGMT, "")
+	if updated.Before(created) {
+		updated = created
+	}
+	return &models.Article{
+		ID:           strconv.FormatInt(id, 10),
+		CreatedAt:    ctypes.MyTime(created),
+		UpdatedAt:    ctypes.MyTime(updated),
+		Title:        title,
+		Abstract:     abstract,
+		Content:      markdown,
+		CommentCount: uint(len(comments)),
+		UserID:       user.ID,
+		UserName:     user.Nickname,
+		Category:     categories,
+		Tags:         tags,
+		Status:       status,
+		PublishAt:    ctypes.MyTime(publishAt),
+	}, comments, nil
+}
+
+// commentsImport 按时间顺序导入评论，保留回复关系，父评论没有导入时作为顶级评论
+func (im *wordpressImporter) commentsImport(articleID string, comments []wxrComment) {
+	slices.SortStableFunc(comments, func(a, b wxrComment) int {
+		if c := wxrTime(a.DateGMT, a.Date).Compare(wxrTime(b.DateGMT, b.Date)); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+
+	ids := make(map[int]uint, len(comments))
+	for _, comment := range comments {
+		var user *models.UserModel
+		var err error
+		if author, ok := im.authorIDs[comment.UserID]; ok && comment.UserID != 0 {
+			user, err = im.userMatch(author.DisplayName, author.Email, author.Login)
+		} else {
+			user, err = im.userMatch(comment.Author, comment.Email, "")
+		}
+		if err != nil {
+			im.report.CommentsFailed++
+			global.Log.Error("匹配评论用户失败", zap.String("author", comment.Author), zap.String("error", err.Error()))
+			continue
+		}
+
+		model := &models.CommentModel{
+			Content:   comment.Content,
+			ArticleID: articleID,
+			UserID:    user.ID,
+		}
+		model.CreatedAt = ctypes.MyTime(wxrTime(comment.DateGMT, comment.Date))
+		if parentID, ok := ids[comment.Parent]; ok {
+			model.ParentCommentID = &parentID
+		}
+		if err := models.CommentImport(model); err != nil {
+			im.report.CommentsFailed++
+			global.Log.Error("导入评论失败", zap.Int("comment_id", comment.ID), zap.String("error", err.Error()))
+			continue
+		}
+		ids[comment.ID] = model.ID
+		im.report.Comments++
+	}
+}
+
+// authorUser 文章作者对应的用户
+func (im *wordpressImporter) authorUser(login string) (*models.UserModel, error) {
+	author, ok := im.authors[login]
+	if !ok {
+		return im.userMatch(login, "", login)
+	}
+	name := author.DisplayName
+	if name == "" {
+		name = author.Login
+	}
+	return im.userMatch(name, author.Email, author.Login)
+}
+
+// userMatch 按邮箱匹配已有用户，文章作者还可以按登录名匹配账号，找不到时创建占位用户。
+// 不按昵称匹配，避免把导入的内容算到同名的已有用户(如管理员)名下
+func (im *wordpressImporter) userMatch(name, email, login string) (*models.UserModel, error) {
+	name = strings.TrimSpace(name)
+	email = strings.ToLower(strings.TrimSpace(email))
+	login = strings.TrimSpace(login)
+	key := email
+	if key == "" && login != "" {
+		key = "login:" + login
+	}
+	if key == "" {
+		key = "name:" + name
+	}
+	if user, ok := im.users[key]; ok {
+		return user, nil
+	}
+
+	var user models.UserModel
+	err := gorm.ErrRecordNotFound
+	if email != "" {
+		err = global.DB.Where("email = ?", email).Take(&user).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && login != "" {
+		err = user.FindByAccount(login)
+	}
+	switch {
+	case err == nil:
+		im.report.UsersMatched++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := placeholderUserCreate(&user, name, email); err != nil {
+			return nil, err
+		}
+		im.report.UsersCreated++
+	default:
+		return nil, err
+	}
+	im.users[key] = &user
+	return &user, nil
+}
+
+// placeholderUserCreate 为没有账号的作者和评论者创建占位用户，密码随机生成，需要时由管理员重置。
+// 昵称不能重复，与已有用户重名时加上账号后缀
+func placeholderUserCreate(user *models.UserModel, name, email string) error {
+	id, err := utils.GenerateID()
+	if err != nil {
+		return err
+	}
+	account := strconv.FormatInt(id, 10)
+	nickname := []rune(name)
+	if len(nickname) > 50 {
+		nickname = nickname[:50]
+	}
+	if len(nickname) < 2 {
+		nickname = []rune("wp_" + account)
+	} else {
+		var existing models.UserModel
+		err := existing.FindByNickname(string(nickname))
+		if err == nil {
+			suffix := []rune("_" + account[len(account)-6:])
+			nickname = append(nickname[:min(len(nickname), 50-len(suffix))], suffix...)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	*user = models.UserModel{
+		Account:  account,
+		Nickname: string(nickname),
+		Password: utils.GenerateRandomString(16),
+		Email:    email,
+		Role:     ctypes.RoleUser,
+	}
+	return user.Create("127.0.0.1")
+}
+
+// wxrStatus 将 WordPress 的文章状态映射为文章状态，回收站和自动草稿不导入
+func wxrStatus(item wxrItem) (ctypes.ArticleStatus, time.Time, bool) {
+	publishAt := wxrTime(item.PostDateGMT, item.PostDate)
+	switch item.Status {
+	case "publish":
+		if publishAt.IsZero() {
+			publishAt = time.Now()
+		}
+		return ctypes.StatusPublished, publishAt, true
+	case "future":
+		if publishAt.After(time.Now()) {
+			return ctypes.StatusScheduled, publishAt, true
+		}
+		return ctypes.StatusPublished, publishAt, true
+	case "draft", "pending", "private":
+		return ctypes.StatusDraft, time.Time{}, true
+	}
+	return "", time.Time{}, false
+}
+
+// wxrTime 优先使用 GMT 时间，未发布的内容 GMT 时间为 0000-00-00 00:00:00，此时使用站点本地时间
+func wxrTime(gmt, local string) time.Time {
+	if t, err := time.ParseInLocation(time.DateTime, gmt, time.UTC); err == nil {
+		return t.Local()
+	}
+	if t, err := time.ParseInLocation(time.DateTime, local, time.Local); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// wpautop 旧版编辑器保存的正文没有 <p> 标签，按空行分段，段内换行转为 <br>
+func wpautop(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if strings.Contains(content, "<p>") || strings.Contains(content, "<p ") {
+		return content
+	}
+
+	var b strings.Builder
+	for _, block := range wpParagraphSplit.Split(content, -1) {
+		block = strings.TrimSpace(block)
+		switch {
+		case block == "":
+			continue
+		case wpBlockRegexp.MatchString(block):
+			b.WriteString(block)
+		default:
+			b.WriteString("<p>" + strings.ReplaceAll(block, "\n", "<br>\n") + "</p>")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
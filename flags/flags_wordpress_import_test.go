@@ -0,0 +1,33 @@
+package flags
+
+import "testing"
+
+func TestWpautop(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "空正文", content: "", want: ""},
+		{name: "单段", content: "第一行", want: "<p>第一行</p>\n"},
+		{name: "段内换行", content: "第一行\n第二行", want: "<p>第一行<br>\n第二行</p>\n"},
+		{name: "空行分段", content: "第一段\n\n第二段", want: "<p>第一段</p>\n<p>第二段</p>\n"},
+		{name: "空白行和多个空行", content: "第一段\n  \n\n\n第二段\n", want: "<p>第一段</p>\n<p>第二段</p>\n"},
+		{name: "Windows 换行", content: "第一段\r\n\r\n第二段", want: "<p>第一段</p>\n<p>第二段</p>\n"},
+		{
+			name:    "块级元素不包裹",
+			content: "<h2>标题</h2>\n\n正文\n\n<pre>a\nb</pre>",
+			want:    "<h2>标题</h2>\n<p>正文</p>\n<pre>a\nb</pre>\n",
+		},
+		{name: "行内元素仍然分段", content: "<strong>加粗</strong>", want: "<p><strong>加粗</strong></p>\n"},
+		{name: "已有段落标签", content: "<p>第一段</p>\n\n第二段", want: "<p>第一段</p>\n\n第二段"},
+		{name: "带属性的段落标签", content: "<p class=\"a\">第一段</p>", want: "<p class=\"a\">第一段</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wpautop(tt.content); got != tt.want {
+				t.Errorf("wpautop(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
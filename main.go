@@ -6,6 +6,7 @@ import (
 	"blog/core"
 	"blog/flags"
 	"blog/global"
+	"blog/models"
 	"blog/router"
 	"blog/service/corn_ser"
 	"blog/utils"
@@ -19,6 +20,8 @@ func main() {
 	core.InitConf()
 	// 初始化日志
 	global.Log = core.NewLogManager(&global.Config.Log)
+	// 初始化敏感词
+	models.InitSensitiveWords()
 	// 初始化数据库
 	global.DB = core.InitGorm()
 	// 初始化redis
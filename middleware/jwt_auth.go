@@ -102,4 +102,27 @@ func JwtAdmin() gin.HandlerFunc {
 	}
 }
 
+// JwtOptional 中间件，携带有效 Token 时将用户信息存储到上下文，否则以游客身份继续
+func JwtOptional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.Request.Header.Get("Authorization")
+		if len(tokenString) < 7 || tokenString[:7] != "Bearer " {
+			c.Next()
+			return
+		}
+		tokenString = tokenString[7:]
+
+		isBlacklisted, err := redis_ser.IsTokenBlacklisted(tokenString)
+		if err != nil || isBlacklisted {
+			c.Next()
+			return
+		}
+
+		claims, err := utils.ParseToken(tokenString)
+		if err == nil {
+			c.Set("claims", claims)
+		}
 
+		c.Next()
+	}
+}
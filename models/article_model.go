@@ -3,44 +3,105 @@ package models
 import (
 	"blog/global"
 	"blog/models/ctypes"
+	"blog/utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/calendarinterval"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/fieldtype"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/highlighterencoder"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/textquerytype"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
 // Article 文章模型
 type Article struct {
-	ID            string        `json:"id"`
-	CreatedAt     ctypes.MyTime `json:"created_at"`     // 创建时间
-	UpdatedAt     ctypes.MyTime `json:"updated_at"`     // 更新时间
-	Title         string        `json:"title"`          // 文章标题
-	Abstract      string        `json:"abstract"`       // 文章简介
-	Content       string        `json:"content"`        // 文章内容
-	LookCount     uint          `json:"look_count"`     // 浏览量
-	CommentCount  uint          `json:"comment_count"`  // 评论量
-	DiggCount     uint          `json:"digg_count"`     // 点赞量
-	CollectsCount uint          `json:"collects_count"` // 收藏量
-	UserID        uint          `json:"user_id"`        // 用户id
-	UserName      string        `json:"user_name"`      // 用户昵称
-	Category      []string      `json:"category"`       // 文章分类
-	CoverID       uint          `json:"cover_id"`       // 封面id
-	CoverURL      string        `json:"cover_url"`      // 封面
-	Version       int64         `json:"version"`        // 版本号
+	ID            string               `json:"id"`
+	CreatedAt     ctypes.MyTime        `json:"created_at"`     // 创建时间
+	UpdatedAt     ctypes.MyTime        `json:"updated_at"`     // 更新时间
+	Title         string               `json:"title"`          // 文章标题
+	Abstract      string               `json:"abstract"`       // 文章简介
+	Content       string               `json:"content"`        // 文章内容
+	LookCount     uint                 `json:"look_count"`     // 浏览量
+	CommentCount  uint                 `json:"comment_count"`  // 评论量
+	DiggCount     uint                 `json:"digg_count"`     // 点赞量
+	CollectsCount uint                 `json:"collects_count"` // 收藏量
+	UserID        uint                 `json:"user_id"`        // 用户id
+	UserName      string               `json:"user_name"`      // 用户昵称
+	Category      []string             `json:"category"`       // 文章分类
+	Tags          []string             `json:"tags"`           // 文章标签
+	CoverID       uint                 `json:"cover_id"`       // 封面id
+	CoverURL      string               `json:"cover_url"`      // 封面
+	Version       int64                `json:"version"`        // 版本号
+	Status        ctypes.ArticleStatus `json:"status"`         // 文章状态
+	PublishAt     ctypes.MyTime        `json:"publish_at"`     // 发布时间
+	SeriesID      uint                 `json:"series_id"`      // 所属系列id，0 表示不属于任何系列
+	SeriesOrder   int                  `json:"series_order"`   // 在系列中的顺序，从 0 开始
+	Slug          string               `json:"slug"`           // 由标题生成的永久链接
+	SlugBase      string               `json:"slug_base"`      // 生成当前 slug 时标题对应的 slug，重复时 slug 会在此基础上加序号
+	OldSlugs      []string             `json:"old_slugs"`      // 修改标题前使用过的 slug，访问时重定向到当前 slug
+	ContentHTML   string               `json:"content_html"`   // 由内容渲染出的 HTML，标题带有锚点
+	Toc           []*utils.TocItem     `json:"toc"`            // 文章目录
+	WordCount     int                  `json:"word_count"`     // 字数
+	ReadingTime   int                  `json:"reading_time"`   // 预计阅读时间，单位分钟
+	Pinned        bool                 `json:"pinned"`         // 是否置顶，默认排序时排在最前
+	PinnedUntil   *ctypes.MyTime       `json:"pinned_until"`   // 置顶到期时间，为空表示不会自动取消
+	Featured      bool                 `json:"featured"`       // 是否精选
+	Visibility    ctypes.Visibility    `json:"visibility"`     // 可见性，为空等同于公开
+
+	// 随文章一起写入的访问密码哈希，为 nil 时不修改，空字符串表示清除
+	passwordHash *string
+	// 读取文章时的 seq_no 和 primary_term，条件更新时用于检测读取之后的任何修改
+	seqNo       *int64
+	primaryTerm *int64
+}
+
+// articleDocument 写入索引的文章文档，访问密码哈希与文章在同一个请求中写入，但不会随 Article 返回
+type articleDocument struct {
+	*Article
+	PasswordHash *string `json:"password_hash,omitempty"`
+}
+
+// document 写入索引的文档
+func (a *Article) document() articleDocument {
+	return articleDocument{Article: a, PasswordHash: a.passwordHash}
 }
 
 const (
-	articleIndex = "article_index"
-	batchSize    = 1000
-	timeout      = time.Second * 5
+	articleIndex   = "article_index" // 索引别名，实际数据存放在 article_index_v{n} 中
+	batchSize      = 1000
+	timeout        = time.Second * 5
+	reindexTimeout = time.Minute * 10
+
+	defaultHighlightFragmentSize = 100
+	defaultHighlightFragments    = 3
+)
+
+var (
+	ErrInvalidArticleStatus = errors.New("无效的文章状态")
+	ErrPublishAtRequired    = errors.New("定时发布需要设置一个未来的发布时间")
+	ErrVersionConflict      = errors.New("文章已被他人修改")
+	ErrArticleNotFound      = errors.New("文章不存在")
+	ErrInvalidVisibility    = errors.New("无效的文章可见性")
+	ErrPasswordRequired     = errors.New("密码保护的文章需要设置访问密码")
 )
 
+// listSourceExcludes 列表类查询不需要返回的大字段
+var listSourceExcludes = []string{"content", "content_html", "toc"}
+
 // ArticleService 文章服务
 type ArticleService struct {
 	ctx          context.Context
@@ -57,16 +118,43 @@ type DateRange struct {
 // SearchParams 搜索参数
 type SearchParams struct {
 	PageInfo
-	SortField string    `json:"sort_field" form:"sort_field"`
-	SortOrder string    `json:"sort_order" form:"sort_order"`
-	Category  []string  `json:"category" form:"category"`
-	DateRange DateRange `json:"date_range" form:"date_range"`
+	SortField   string               `json:"sort_field" form:"sort_field"`
+	SortOrder   string               `json:"sort_order" form:"sort_order"`
+	Category    []string             `json:"category" form:"category"`
+	Tags        []string             `json:"tags" form:"tags"`
+	DateRange   DateRange            `json:"date_range" form:"date_range"`
+	Status      ctypes.ArticleStatus `json:"status" form:"status"`
+	Featured    bool                 `json:"featured" form:"featured"` // 只返回精选文章
+	IsAdmin     bool                 `json:"-" form:"-"`               // 非管理员只能搜索到已发布的公开文章
+	UserID      uint                 `json:"-" form:"-"`               // 当前登录用户，可以搜索到自己的非公开文章
+	WithContent bool                 `json:"-" form:"-"`               // 是否返回文章正文，列表页不需要
+	WithFacets  bool                 `json:"-" form:"-"`               // 是否返回分类和月份聚合
+}
+
+// FacetBucket 聚合桶
+type FacetBucket struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets 与搜索条件一致的分类和月份统计
+type SearchFacets struct {
+	Categories []FacetBucket `json:"categories"`
+	Months     []FacetBucket `json:"months"`
 }
 
 // SearchResult 搜索结果
 type SearchResults struct {
-	Articles []Article
-	Total    int64
+	Articles   []Article
+	Highlights map[string]map[string][]string // 文章id -> 字段 -> 高亮片段
+	Facets     *SearchFacets
+	Total      int64
+}
+
+// ArticleSuggestion 标题输入提示
+type ArticleSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
 }
 
 // NewArticleService 创建文章服务实例
@@ -79,7 +167,60 @@ func NewArticleService() *ArticleService {
 	}
 }
 
-// IndexCreate 创建索引
+// articleMapping 文章索引映射
+func articleMapping() *types.TypeMapping {
+	disabled := false
+	return &types.TypeMapping{
+		// 设置索引的映射规则
+		Properties: map[string]types.Property{
+			"id": types.NewKeywordProperty(),
+			"title": &types.TextProperty{
+				// 标题的输入提示子字段
+				Fields: map[string]types.Property{"suggest": types.NewSearchAsYouTypeProperty()},
+			},
+			"abstract":       types.NewTextProperty(),
+			"content":        types.NewTextProperty(),
+			"category":       types.NewKeywordProperty(),
+			"tags":           types.NewKeywordProperty(),
+			"created_at":     types.NewDateProperty(),
+			"updated_at":     types.NewDateProperty(),
+			"look_count":     types.NewIntegerNumberProperty(),
+			"comment_count":  types.NewIntegerNumberProperty(),
+			"digg_count":     types.NewIntegerNumberProperty(),
+			"collects_count": types.NewIntegerNumberProperty(),
+			"user_id":        types.NewIntegerNumberProperty(),
+			"user_name":      types.NewKeywordProperty(),
+			"cover_id":       types.NewIntegerNumberProperty(),
+			"cover_url":      types.NewKeywordProperty(),
+			"version":        types.NewLongNumberProperty(),
+			"status":         types.NewKeywordProperty(),
+			"publish_at":     types.NewDateProperty(),
+			"series_id":      types.NewIntegerNumberProperty(),
+			"series_order":   types.NewIntegerNumberProperty(),
+			"slug":           types.NewKeywordProperty(),
+			"slug_base":      types.NewKeywordProperty(),
+			"old_slugs":      types.NewKeywordProperty(),
+			// 渲染结果只用于展示，不参与检索
+			"content_html": &types.TextProperty{Index: &disabled},
+			"toc":          &types.ObjectProperty{Enabled: &disabled},
+			"word_count":   types.NewIntegerNumberProperty(),
+			"reading_time": types.NewIntegerNumberProperty(),
+			"pinned":       types.NewBooleanProperty(),
+			"pinned_until": types.NewDateProperty(),
+			"featured":     types.NewBooleanProperty(),
+			"visibility":   types.NewKeywordProperty(),
+			// 只用于校验访问密码
+			"password_hash": &types.KeywordProperty{Index: &disabled},
+		},
+	}
+}
+
+// versionedIndex 返回第 version 版物理索引的名称
+func (s *ArticleService) versionedIndex(version int) string {
+	return fmt.Sprintf("%s_v%d", s.articleIndex, version)
+}
+
+// IndexCreate 创建第一版物理索引并挂上别名，索引已存在时不做任何修改
 func (s *ArticleService) IndexCreate() error {
 	if s.ctx == nil {
 		s.ctx = context.Background()
@@ -94,35 +235,13 @@ func (s *ArticleService) IndexCreate() error {
 	}
 
 	if exist {
-		if err := s.IndexDelete(); err != nil {
-			return fmt.Errorf("删除已存在的索引失败: %w", err)
-		}
-	}
-
-	// 索引映射
-	properties := map[string]types.Property{
-		"title":          types.NewTextProperty(),
-		"abstract":       types.NewTextProperty(),
-		"content":        types.NewTextProperty(),
-		"category":       types.NewKeywordProperty(),
-		"created_at":     types.NewDateProperty(),
-		"updated_at":     types.NewDateProperty(),
-		"look_count":     types.NewIntegerNumberProperty(),
-		"comment_count":  types.NewIntegerNumberProperty(),
-		"digg_count":     types.NewIntegerNumberProperty(),
-		"collects_count": types.NewIntegerNumberProperty(),
-		"user_id":        types.NewIntegerNumberProperty(),
-		"user_name":      types.NewKeywordProperty(),
-		"cover_id":       types.NewIntegerNumberProperty(),
-		"cover_url":      types.NewKeywordProperty(),
-		"version":        types.NewLongNumberProperty(),
-	}
-
-	_, err = global.Es.Indices.Create(articleIndex).
-		Mappings(&types.TypeMapping{
-			// 设置索引的映射规则
-			Properties: properties,
-		}).
+		global.Log.Info("索引已存在，如需更新映射请使用 reindex 命令", zap.String("index", s.articleIndex))
+		return nil
+	}
+
+	_, err = global.Es.Indices.Create(s.versionedIndex(1)).
+		Mappings(articleMapping()).
+		Aliases(map[string]types.Alias{s.articleIndex: {}}).
 		Do(ctx)
 
 	if err != nil {
@@ -158,6 +277,293 @@ func (s *ArticleService) IndexDelete() error {
 	return nil
 }
 
+// indexCurrent 获取别名当前指向的物理索引，legacy 表示 article_index 仍是未使用别名的旧索引
+func (s *ArticleService) indexCurrent(ctx context.Context) (index string, legacy bool, err error) {
+	isAlias, err := global.Es.Indices.ExistsAlias(s.articleIndex).Do(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("检查别名是否存在失败: %w", err)
+	}
+	if isAlias {
+		resp, err := global.Es.Indices.GetAlias().Name(s.articleIndex).Do(ctx)
+		if err != nil {
+			return "", false, fmt.Errorf("获取别名失败: %w", err)
+		}
+		if len(resp) != 1 {
+			return "", false, fmt.Errorf("别名 %s 指向了 %d 个索引", s.articleIndex, len(resp))
+		}
+		for name := range resp {
+			index = name
+		}
+		return index, false, nil
+	}
+
+	exists, err := global.Es.Indices.Exists(s.articleIndex).Do(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("检查索引是否存在失败: %w", err)
+	}
+	if !exists {
+		return "", false, fmt.Errorf("索引 %s 不存在", s.articleIndex)
+	}
+	return s.articleIndex, true, nil
+}
+
+// indexNextVersion 计算下一个物理索引的版本号
+func (s *ArticleService) indexNextVersion(ctx context.Context) (int, error) {
+	resp, err := global.Es.Indices.Get(s.articleIndex + "_v*").Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取索引列表失败: %w", err)
+	}
+
+	latest := 0
+	for name := range resp {
+		version, err := strconv.Atoi(strings.TrimPrefix(name, s.articleIndex+"_v"))
+		if err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest + 1, nil
+}
+
+// indexCopy 将 source 的文档复制到 dest，使用外部版本号，只覆盖 dest 中版本更旧的文档
+func (s *ArticleService) indexCopy(ctx context.Context, source, dest string) error {
+	resp, err := global.Es.Reindex().
+		Source(&types.ReindexSource{Index: []string{source}}).
+		Dest(&types.ReindexDestination{Index: dest, VersionType: &versiontype.External}).
+		Conflicts(conflicts.Proceed).
+		WaitForCompletion(true).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("复制文档失败: %w", err)
+	}
+	if len(resp.Failures) > 0 {
+		return fmt.Errorf("复制文档时有 %d 条失败", len(resp.Failures))
+	}
+	return nil
+}
+
+// indexWriteBlock 设置索引是否禁止写入
+func (s *ArticleService) indexWriteBlock(ctx context.Context, index string, block bool) error {
+	_, err := global.Es.Indices.PutSettings().
+		Indices(index).
+		Blocks(&types.IndexSettingBlocks{Write: block}).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置索引写入限制失败: %w", err)
+	}
+	return nil
+}
+
+// indexIDs 遍历索引，返回其中全部文档的 id
+func (s *ArticleService) indexIDs(ctx context.Context, index string) (map[string]struct{}, error) {
+	keepAlive := "1m"
+	pit, err := global.Es.OpenPointInTime(index).KeepAlive(keepAlive).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("打开 point in time 失败: %w", err)
+	}
+	pitID := pit.Id
+	defer func() {
+		if _, err := global.Es.ClosePointInTime().Id(pitID).Do(context.Background()); err != nil {
+			global.Log.Error("关闭 point in time 失败", zap.String("error", err.Error()))
+		}
+	}()
+
+	ids := make(map[string]struct{})
+	var searchAfter []types.FieldValue
+	for {
+		req := global.Es.Search().
+			Pit(&types.PointInTimeReference{Id: pitID, KeepAlive: keepAlive}).
+			Source_(false).
+			Sort("_shard_doc").
+			Size(s.batchSize)
+		if searchAfter != nil {
+			req.SearchAfter(searchAfter...)
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("遍历索引 %s 失败: %w", index, err)
+		}
+		if resp.PitId != nil {
+			pitID = *resp.PitId
+		}
+		if len(resp.Hits.Hits) == 0 {
+			return ids, nil
+		}
+		for _, hit := range resp.Hits.Hits {
+			ids[*hit.Id_] = struct{}{}
+		}
+		searchAfter = resp.Hits.Hits[len(resp.Hits.Hits)-1].Sort
+	}
+}
+
+// indexPrune 删除 dest 中在 source 里已不存在的文档，复制只会新增和覆盖，复制期间删除的文章需要单独处理
+func (s *ArticleService) indexPrune(ctx context.Context, source, dest string) error {
+	sourceIDs, err := s.indexIDs(ctx, source)
+	if err != nil {
+		return err
+	}
+	destIDs, err := s.indexIDs(ctx, dest)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for id := range destIDs {
+		if _, ok := sourceIDs[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+
+	for i := 0; i < len(stale); i += s.batchSize {
+		end := min(i+s.batchSize, len(stale))
+		resp, err := global.Es.DeleteByQuery(dest).
+			Query(&types.Query{Ids: &types.IdsQuery{Values: stale[i:end]}}).
+			Refresh(true).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("删除已失效的文档失败: %w", err)
+		}
+		if len(resp.Failures) > 0 {
+			return fmt.Errorf("删除已失效的文档时有 %d 条失败", len(resp.Failures))
+		}
+	}
+	return nil
+}
+
+// indexLegacyMigrate 将未使用别名的旧索引克隆为一个物理索引，再把 article_index 改为指向它的别名。
+// 旧数据完整保留在克隆出的索引中，之后的重建可以回滚到它
+func (s *ArticleService) indexLegacyMigrate(ctx context.Context) (string, error) {
+	version, err := s.indexNextVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	index := s.versionedIndex(version)
+
+	// 克隆要求源索引禁止写入
+	if err := s.indexWriteBlock(ctx, s.articleIndex, true); err != nil {
+		return "", err
+	}
+	_, err = global.Es.Indices.Clone(s.articleIndex, index).Do(ctx)
+	if err != nil {
+		s.indexWriteBlock(ctx, s.articleIndex, false)
+		return "", fmt.Errorf("克隆索引失败: %w", err)
+	}
+	_, err = global.Es.Indices.UpdateAliases().Actions(
+		types.IndicesAction{RemoveIndex: &types.RemoveIndexAction{Index: &s.articleIndex}},
+		types.IndicesAction{Add: &types.AddAction{Index: &index, Alias: &s.articleIndex}},
+	).Do(ctx)
+	if err != nil {
+		s.indexWriteBlock(ctx, s.articleIndex, false)
+		return "", fmt.Errorf("切换别名失败: %w", err)
+	}
+	// 克隆出的索引沿用了写入限制，解除后迁移期间仍可正常读写
+	if err := s.indexWriteBlock(ctx, index, false); err != nil {
+		return "", err
+	}
+
+	global.Log.Info("旧索引已迁移为别名",
+		zap.String("alias", s.articleIndex),
+		zap.String("index", index),
+	)
+	return index, nil
+}
+
+// IndexReindex 按最新映射创建新版本的物理索引并迁移数据，完成后原子地切换别名
+// 旧索引会保留并禁止写入，可通过 IndexRollback 回滚；若 article_index 是未使用别名的旧索引，会先将其克隆为物理索引再迁移
+func (s *ArticleService) IndexReindex() (string, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, reindexTimeout)
+	defer cancel()
+
+	current, legacy, err := s.indexCurrent(ctx)
+	if err != nil {
+		return "", err
+	}
+	if legacy {
+		current, err = s.indexLegacyMigrate(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+	version, err := s.indexNextVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	newIndex := s.versionedIndex(version)
+
+	_, err = global.Es.Indices.Create(newIndex).
+		Mappings(articleMapping()).
+		Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("创建索引失败: %w", err)
+	}
+
+	// 第一轮全量复制，期间旧索引仍可正常读写
+	if err := s.indexCopy(ctx, current, newIndex); err != nil {
+		return "", err
+	}
+
+	// 禁止旧索引写入后再复制一轮，补上第一轮期间发生的修改，并删除期间被删除的文章
+	if err := s.indexWriteBlock(ctx, current, true); err != nil {
+		return "", err
+	}
+	if err := s.indexCopy(ctx, current, newIndex); err != nil {
+		s.indexWriteBlock(ctx, current, false)
+		return "", err
+	}
+	if err := s.indexPrune(ctx, current, newIndex); err != nil {
+		s.indexWriteBlock(ctx, current, false)
+		return "", err
+	}
+
+	_, err = global.Es.Indices.UpdateAliases().Actions(
+		types.IndicesAction{Remove: &types.RemoveAction{Index: &current, Alias: &s.articleIndex}},
+		types.IndicesAction{Add: &types.AddAction{Index: &newIndex, Alias: &s.articleIndex}},
+	).Do(ctx)
+	if err != nil {
+		s.indexWriteBlock(ctx, current, false)
+		return "", fmt.Errorf("切换别名失败: %w", err)
+	}
+
+	global.Log.Info("重建索引成功",
+		zap.String("from", current),
+		zap.String("to", newIndex),
+	)
+	return newIndex, nil
+}
+
+// IndexRollback 将别名切换回指定的物理索引
+func (s *ArticleService) IndexRollback(index string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	current, legacy, err := s.indexCurrent(ctx)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		return fmt.Errorf("索引 %s 尚未使用别名，无法回滚", s.articleIndex)
+	}
+	if current == index {
+		return nil
+	}
+
+	if err := s.indexWriteBlock(ctx, index, false); err != nil {
+		return err
+	}
+	_, err = global.Es.Indices.UpdateAliases().Actions(
+		types.IndicesAction{Remove: &types.RemoveAction{Index: &current, Alias: &s.articleIndex}},
+		types.IndicesAction{Add: &types.AddAction{Index: &index, Alias: &s.articleIndex}},
+	).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("切换别名失败: %w", err)
+	}
+
+	global.Log.Info("回滚索引成功",
+		zap.String("from", current),
+		zap.String("to", index),
+	)
+	return nil
+}
+
 // ArticleCreate 创建文章
 func (s *ArticleService) ArticleCreate(article *Article) error {
 	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
@@ -170,13 +576,18 @@ func (s *ArticleService) ArticleCreate(article *Article) error {
 	if exists {
 		return fmt.Errorf("文章已存在")
 	}
-	article.CreatedAt = ctypes.MyTime(time.Now())
-	article.UpdatedAt = ctypes.MyTime(time.Now())
+	// 导入的文章保留原有的创建和更新时间
+	if time.Time(article.CreatedAt).IsZero() {
+		article.CreatedAt = ctypes.MyTime(time.Now())
+	}
+	if time.Time(article.UpdatedAt).IsZero() {
+		article.UpdatedAt = article.CreatedAt
+	}
 	article.Version = 1
 
 	_, err = global.Es.Index(s.articleIndex).
 		Id(article.ID).
-		Document(article).
+		Document(article.document()).
 		Refresh(refresh.True).
 		Do(ctx)
 
@@ -201,27 +612,86 @@ func (s *ArticleService) ArticleGet(id string) (*Article, error) {
 	if err := json.Unmarshal(resp.Source_, &result); err != nil {
 		return nil, fmt.Errorf("解析文章数据失败: %w", err)
 	}
+	result.seqNo, result.primaryTerm = resp.SeqNo_, resp.PrimaryTerm_
 
 	return &result, nil
 }
 
-// ArticleUpdate 更新文章
-func (s *ArticleService) ArticleUpdate(article *Article) error {
+// ArticleUpdateIfVersion 在客户端持有的版本号与当前版本一致时更新文章，否则返回 ErrVersionConflict。
+// article 必须由 ArticleGet 读取，读取之后文章有任何修改(包括不改变版本号的置顶、统计等)都视为冲突，
+// 避免把读取时的旧字段写回
+func (s *ArticleService) ArticleUpdateIfVersion(article *Article, version int64) error {
 	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
 	defer cancel()
 
-	article.Version++
-	article.UpdatedAt = ctypes.MyTime(time.Now())
+	if article.seqNo == nil || article.primaryTerm == nil {
+		return fmt.Errorf("文章 %s 缺少读取时的 seq_no", article.ID)
+	}
+	if article.Version != version {
+		return ErrVersionConflict
+	}
 
-	_, err := global.Es.Update(s.articleIndex, article.ID).
-		Doc(article).
+	current := *article
+	current.Version = version + 1
+	current.UpdatedAt = ctypes.MyTime(time.Now())
+
+	resp, err := global.Es.Update(s.articleIndex, article.ID).
+		IfSeqNo(strconv.FormatInt(*article.seqNo, 10)).
+		IfPrimaryTerm(strconv.FormatInt(*article.primaryTerm, 10)).
+		Doc(current.document()).
 		Refresh(refresh.True).
 		Do(ctx)
-
 	if err != nil {
+		var esErr *types.ElasticsearchError
+		if errors.As(err, &esErr) && esErr.Status == http.StatusConflict {
+			return ErrVersionConflict
+		}
 		return fmt.Errorf("更新文章失败: %w", err)
 	}
 
+	current.seqNo, current.primaryTerm = resp.SeqNo_, resp.PrimaryTerm_
+	*article = current
+	return nil
+}
+
+// ArticleRevise 以客户端持有的版本号更新文章，并保存新版本的修订记录。
+// 修订记录先于文章写入，文章写入失败时删除，保证每个版本都有修订记录，失败后也可以直接重试
+func (s *ArticleService) ArticleRevise(article *Article, version int64, editorID uint) error {
+	if article.Version != version {
+		return ErrVersionConflict
+	}
+
+	snapshot := *article
+	snapshot.Version = version + 1
+	if err := RevisionCreate(&snapshot, editorID); err != nil {
+		// 另一个基于相同版本的修改正在写入或已经完成
+		if errors.Is(err, ErrRevisionExists) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+	if err := s.ArticleUpdateIfVersion(article, version); err != nil {
+		if removeErr := RevisionRemove(article.ID, snapshot.Version); removeErr != nil {
+			global.Log.Error("RevisionRemove() failed", zap.String("error", removeErr.Error()))
+		}
+		return err
+	}
+	return nil
+}
+
+// ArticleStatsUpdate 更新文章的统计字段，不改变文章版本号
+func (s *ArticleService) ArticleStatsUpdate(id string, stats map[string]uint) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	_, err := global.Es.Update(s.articleIndex, id).
+		Doc(stats).
+		Refresh(refresh.True).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("更新文章统计数据失败: %w", err)
+	}
+
 	return nil
 }
 
@@ -299,16 +769,35 @@ func (s *ArticleService) ArticleSearch(params SearchParams) (*SearchResults, err
 		// 	},
 		// })
 		//
-		// 匹配多个分类
+		// 匹配多个分类，每个分类展开为它的子树，命中子树中任意分类即可
+		subtrees, err := CategorySubtreeNames(params.Category)
+		if err != nil {
+			global.Log.Error("展开分类子树失败", zap.Strings("category", params.Category), zap.Error(err))
+		}
 		for _, category := range params.Category {
+			subtree, ok := subtrees[category]
+			if !ok {
+				subtree = []string{category}
+			}
 			boolQuery.Filter = append(boolQuery.Filter, types.Query{
-				Term: map[string]types.TermQuery{
-					"category": {Value: category},
+				Terms: &types.TermsQuery{
+					TermsQuery: map[string]types.TermsQueryField{
+						"category": subtree,
+					},
 				},
 			})
 		}
 	}
 
+	// 3.1 标签过滤，需同时包含所有标签
+	for _, tag := range params.Tags {
+		boolQuery.Filter = append(boolQuery.Filter, types.Query{
+			Term: map[string]types.TermQuery{
+				"tags": {Value: tag},
+			},
+		})
+	}
+
 	// 4. 日期范围过滤
 	if params.DateRange.Start != "" && params.DateRange.End != "" {
 		rangeQuery := types.NewDateRangeQuery()
@@ -319,6 +808,26 @@ func (s *ArticleService) ArticleSearch(params SearchParams) (*SearchResults, err
 		})
 	}
 
+	// 5. 状态和可见性过滤
+	if !params.IsAdmin {
+		boolQuery.Filter = append(boolQuery.Filter, publishedQuery(), visibleQuery(params.UserID))
+	} else if params.Status != "" {
+		boolQuery.Filter = append(boolQuery.Filter, types.Query{
+			Term: map[string]types.TermQuery{
+				"status": {Value: params.Status},
+			},
+		})
+	}
+
+	// 5.1 精选过滤
+	if params.Featured {
+		boolQuery.Filter = append(boolQuery.Filter, types.Query{
+			Term: map[string]types.TermQuery{
+				"featured": {Value: true},
+			},
+		})
+	}
+
 	// 6. 分页处理
 	page := params.PageInfo.Page
 	if page <= 0 {
@@ -330,37 +839,65 @@ func (s *ArticleService) ArticleSearch(params SearchParams) (*SearchResults, err
 	}
 	from := (page - 1) * pageSize
 
-	// 7. 排序处理
+	// 7. 排序处理，未指定排序字段时置顶文章排在最前
 	sortField := params.SortField
 	sortOrder := params.SortOrder
 
+	var sorts []types.SortCombinations
 	if sortField == "" {
 		sortField = "created_at"
+		sorts = append(sorts, types.SortOptions{
+			SortOptions: map[string]types.FieldSort{
+				// 旧索引中可能还没有 pinned 字段
+				"pinned": {Order: &sortorder.Desc, UnmappedType: &fieldtype.Boolean},
+			},
+		})
 	}
 	if sortOrder == "" {
 		sortOrder = "desc"
 	}
+	sorts = append(sorts,
+		types.SortOptions{
+			SortOptions: map[string]types.FieldSort{
+				sortField: {Order: &sortorder.SortOrder{Name: sortOrder}},
+			},
+		},
+		// 排序值相同时按文章id排序，保证分页结果稳定。id 由动态映射生成的旧索引需要先执行 reindex
+		types.SortOptions{
+			SortOptions: map[string]types.FieldSort{
+				"id": {Order: &sortorder.Desc, UnmappedType: &fieldtype.Keyword},
+			},
+		},
+	)
 
 	// 10. 构建搜索请求
 	searchRequest := global.Es.Search().
 		Index(s.articleIndex).
 		Query(&types.Query{Bool: boolQuery}).
-		Sort(types.SortOptions{
-			SortOptions: map[string]types.FieldSort{
-				sortField: {Order: &sortorder.SortOrder{Name: sortOrder}},
-			},
-		}).
+		Sort(sorts...).
 		From(from).
 		Size(pageSize)
 
-	// 12. 执行搜索
+	// 11. 高亮关键词，列表不返回正文
+	if params.PageInfo.Key != "" {
+		searchRequest.Highlight(searchHighlight())
+	}
+	if !params.WithContent {
+		searchRequest.Source_(&types.SourceFilter{Excludes: listSourceExcludes})
+	}
+
+	// 12. 执行搜索，聚合与结果使用同一个查询
+	if params.WithFacets {
+		searchRequest.Aggregations(searchFacetAggregations())
+	}
 	resp, err := searchRequest.Do(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("搜索文章失败: %w", err)
 	}
 
 	// 13. 处理搜索结果
-	articles := make([]Article, 0, int(resp.Hits.Total.Value))
+	articles := make([]Article, 0, len(resp.Hits.Hits))
+	highlights := make(map[string]map[string][]string)
 	for _, hit := range resp.Hits.Hits {
 		var article Article
 		if err := json.Unmarshal(hit.Source_, &article); err != nil {
@@ -371,12 +908,856 @@ func (s *ArticleService) ArticleSearch(params SearchParams) (*SearchResults, err
 			continue
 		}
 		articles = append(articles, article)
+		if len(hit.Highlight) > 0 {
+			highlights[article.ID] = hit.Highlight
+		}
+	}
+
+	results := &SearchResults{
+		Articles:   articles,
+		Highlights: highlights,
+		Total:      resp.Hits.Total.Value,
+	}
+	if params.WithFacets {
+		results.Facets = searchFacetsParse(resp.Aggregations)
+	}
+	return results, nil
+}
+
+// searchHighlight 搜索高亮配置，标题整体高亮，正文按配置截取片段
+func searchHighlight() *types.Highlight {
+	fragmentSize := global.Config.Es.HighlightFragmentSize
+	if fragmentSize <= 0 {
+		fragmentSize = defaultHighlightFragmentSize
+	}
+	fragments := global.Config.Es.HighlightFragments
+	if fragments <= 0 {
+		fragments = defaultHighlightFragments
+	}
+	wholeField := 0
+
+	return &types.Highlight{
+		// 对原文做HTML转义，避免高亮片段中混入标签
+		Encoder: &highlighterencoder.Html,
+		Fields: map[string]types.HighlightField{
+			"title":    {NumberOfFragments: &wholeField},
+			"abstract": {NumberOfFragments: &wholeField},
+			"content":  {FragmentSize: &fragmentSize, NumberOfFragments: &fragments},
+		},
+	}
+}
+
+// searchFacetAggregations 分类和月份聚合
+func searchFacetAggregations() map[string]types.Aggregations {
+	categoryField, categorySize := "category", 100
+	dateField, dateFormat, timeZone, minDocCount := "created_at", "yyyy-MM", "Asia/Shanghai", 1
+
+	return map[string]types.Aggregations{
+		"categories": {
+			Terms: &types.TermsAggregation{
+				Field: &categoryField,
+				Size:  &categorySize,
+			},
+		},
+		"months": {
+			DateHistogram: &types.DateHistogramAggregation{
+				Field:            &dateField,
+				CalendarInterval: &calendarinterval.Month,
+				Format:           &dateFormat,
+				TimeZone:         &timeZone,
+				MinDocCount:      &minDocCount,
+				Order:            map[string]sortorder.SortOrder{"_key": sortorder.Desc},
+			},
+		},
+	}
+}
+
+// searchFacetsParse 解析分类和月份聚合结果
+func searchFacetsParse(aggregations map[string]types.Aggregate) *SearchFacets {
+	facets := &SearchFacets{
+		Categories: make([]FacetBucket, 0),
+		Months:     make([]FacetBucket, 0),
+	}
+
+	if agg, found := aggregations["categories"]; found {
+		var termsAgg types.StringTermsAggregate
+		aggBytes, _ := json.Marshal(agg)
+		if err := json.Unmarshal(aggBytes, &termsAgg); err != nil {
+			global.Log.Error("解析聚合结果失败", zap.String("aggregation", "categories"), zap.Error(err))
+		} else if buckets, ok := termsAgg.Buckets.([]types.StringTermsBucket); ok {
+			for _, bucket := range buckets {
+				facets.Categories = append(facets.Categories, FacetBucket{
+					Key:   fmt.Sprint(bucket.Key),
+					Count: bucket.DocCount,
+				})
+			}
+		}
+	}
+
+	if agg, found := aggregations["months"]; found {
+		var histogramAgg types.DateHistogramAggregate
+		aggBytes, _ := json.Marshal(agg)
+		if err := json.Unmarshal(aggBytes, &histogramAgg); err != nil {
+			global.Log.Error("解析聚合结果失败", zap.String("aggregation", "months"), zap.Error(err))
+		} else if buckets, ok := histogramAgg.Buckets.([]types.DateHistogramBucket); ok {
+			for _, bucket := range buckets {
+				if bucket.KeyAsString == nil {
+					continue
+				}
+				facets.Months = append(facets.Months, FacetBucket{
+					Key:   *bucket.KeyAsString,
+					Count: bucket.DocCount,
+				})
+			}
+		}
+	}
+
+	return facets
+}
+
+// publishedQuery 已发布文章的过滤条件，没有状态字段的旧文档视为已发布
+func publishedQuery() types.Query {
+	return types.Query{
+		Bool: &types.BoolQuery{
+			Should: []types.Query{
+				{Term: map[string]types.TermQuery{"status": {Value: ctypes.StatusPublished}}},
+				{Bool: &types.BoolQuery{MustNot: []types.Query{{Exists: &types.ExistsQuery{Field: "status"}}}}},
+			},
+			MinimumShouldMatch: 1,
+		},
+	}
+}
+
+// Render 根据 Content 渲染 HTML，并提取目录、字数和阅读时间
+func (a *Article) Render() error {
+	rendered, err := utils.RenderMarkdown(a.Content)
+	if err != nil {
+		return err
+	}
+	a.ContentHTML = rendered.HTML
+	a.Toc = rendered.Toc
+	a.WordCount = rendered.WordCount
+	a.ReadingTime = rendered.ReadingTime
+	return nil
+}
+
+// IsPublished 文章是否已发布
+func (a *Article) IsPublished() bool {
+	return a.Status == "" || a.Status == ctypes.StatusPublished
+}
+
+// visibleQuery 公开文章的过滤条件，没有可见性字段的旧文档视为公开，userID 不为 0 时包含该用户自己的文章
+func visibleQuery(userID uint) types.Query {
+	should := []types.Query{
+		{Term: map[string]types.TermQuery{"visibility": {Value: ctypes.VisibilityPublic}}},
+		{Bool: &types.BoolQuery{MustNot: []types.Query{{Exists: &types.ExistsQuery{Field: "visibility"}}}}},
+	}
+	if userID != 0 {
+		should = append(should, types.Query{Term: map[string]types.TermQuery{"user_id": {Value: userID}}})
+	}
+	return types.Query{
+		Bool: &types.BoolQuery{
+			Should:             should,
+			MinimumShouldMatch: 1,
+		},
+	}
+}
+
+// VisibilityApply 设置文章可见性，访问密码哈希在写入文章时一并保存。
+// 已经是密码保护的文章 password 为空时沿用原密码，改为其他可见性时清除密码
+func (a *Article) VisibilityApply(visibility ctypes.Visibility, password string) error {
+	hadPassword := a.Visibility == ctypes.VisibilityPassword
+	switch visibility {
+	case ctypes.VisibilityPublic, ctypes.VisibilityUnlisted, ctypes.VisibilityPrivate:
+		a.Visibility = visibility
+		if hadPassword {
+			hash := ""
+			a.passwordHash = &hash
+		}
+		return nil
+	case ctypes.VisibilityPassword:
+		if password == "" {
+			if !hadPassword {
+				return ErrPasswordRequired
+			}
+			return nil
+		}
+		hash, err := utils.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		a.Visibility = visibility
+		a.passwordHash = &hash
+		return nil
+	default:
+		return ErrInvalidVisibility
+	}
+}
+
+// ArticlePasswordHash 获取文章的访问密码哈希，未设置时返回空字符串。哈希不在 Article 中，不会随文章返回
+func (s *ArticleService) ArticlePasswordHash(id string) (string, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	resp, err := global.Es.Get(s.articleIndex, id).SourceIncludes_("password_hash").Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("获取文章访问密码失败: %w", err)
+	}
+	if !resp.Found {
+		return "", ErrArticleNotFound
+	}
+	var source struct {
+		PasswordHash string `json:"password_hash"`
 	}
+	if err := json.Unmarshal(resp.Source_, &source); err != nil {
+		return "", fmt.Errorf("解析文章数据失败: %w", err)
+	}
+	return source.PasswordHash, nil
+}
 
-	return &SearchResults{
-		Articles: articles,
-		Total:    resp.Hits.Total.Value,
-	}, nil
+// StatusApply 设置文章状态及发布时间
+func (a *Article) StatusApply(status ctypes.ArticleStatus, publishAt ctypes.MyTime) error {
+	switch status {
+	case "", ctypes.StatusPublished:
+		// 首次发布时记录发布时间
+		if !a.IsPublished() || time.Time(a.PublishAt).IsZero() {
+			a.PublishAt = ctypes.MyTime(time.Now())
+		}
+		a.Status = ctypes.StatusPublished
+	case ctypes.StatusDraft, ctypes.StatusArchived:
+		a.Status = status
+	case ctypes.StatusScheduled:
+		if !time.Time(publishAt).After(time.Now()) {
+			return ErrPublishAtRequired
+		}
+		a.Status = status
+		a.PublishAt = publishAt
+	default:
+		return ErrInvalidArticleStatus
+	}
+	return nil
+}
+
+// ScheduledArticlePublish 发布所有到期的定时文章，返回发布的数量。只改变状态，不改变文章版本号
+func (s *ArticleService) ScheduledArticlePublish() (int64, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	now := time.Now().Format(time.RFC3339)
+	resp, err := global.Es.UpdateByQuery(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Filter: []types.Query{
+					{Term: map[string]types.TermQuery{"status": {Value: ctypes.StatusScheduled}}},
+					{Range: map[string]types.RangeQuery{"publish_at": types.DateRangeQuery{Lte: &now}}},
+				},
+			},
+		}).
+		Script(&types.InlineScript{
+			Source: "ctx._source.status = params.status",
+			Params: map[string]json.RawMessage{
+				"status": json.RawMessage(fmt.Sprintf("%q", ctypes.StatusPublished)),
+			},
+		}).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("发布定时文章失败: %w", err)
+	}
+
+	if resp.Updated == nil {
+		return 0, nil
+	}
+	return *resp.Updated, nil
+}
+
+// ArticlePinSet 设置文章置顶，until 为空表示不会自动取消置顶，不改变文章版本号
+func (s *ArticleService) ArticlePinSet(id string, pinned bool, until *ctypes.MyTime) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	if !pinned {
+		until = nil
+	}
+	_, err := global.Es.Update(s.articleIndex, id).
+		Doc(map[string]any{"pinned": pinned, "pinned_until": until}).
+		Refresh(refresh.True).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置文章置顶失败: %w", err)
+	}
+	return nil
+}
+
+// ArticleFeatureSet 设置文章是否精选，不改变文章版本号
+func (s *ArticleService) ArticleFeatureSet(id string, featured bool) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	_, err := global.Es.Update(s.articleIndex, id).
+		Doc(map[string]any{"featured": featured}).
+		Refresh(refresh.True).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置文章精选失败: %w", err)
+	}
+	return nil
+}
+
+// ExpiredPinUnset 取消所有已到期的置顶，返回取消的数量
+func (s *ArticleService) ExpiredPinUnset() (int64, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	now := time.Now().Format(time.RFC3339)
+	resp, err := global.Es.UpdateByQuery(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Filter: []types.Query{
+					{Term: map[string]types.TermQuery{"pinned": {Value: true}}},
+					{Range: map[string]types.RangeQuery{"pinned_until": types.DateRangeQuery{Lte: &now}}},
+				},
+			},
+		}).
+		Script(&types.InlineScript{
+			Source: "ctx._source.pinned = false; ctx._source.pinned_until = null",
+		}).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("取消到期置顶失败: %w", err)
+	}
+
+	if resp.Updated == nil {
+		return 0, nil
+	}
+	return *resp.Updated, nil
+}
+
+// ArticleTermReplace 将所有文章 field 字段中的 from 替换为 to，to 为空时直接移除，返回更新的文章数。
+// 有文章未能修改时返回错误。标签、分类的改名不是文章内容的修改，不改变文章版本号
+func (s *ArticleService) ArticleTermReplace(field, from, to string) (int64, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, reindexTimeout)
+	defer cancel()
+
+	// 保持原有顺序，文章已包含 to 时只移除 from
+	script := `
+		def list = ctx._source[params.field];
+		int i = list == null ? -1 : list.indexOf(params.from);
+		if (i < 0) { ctx.op = 'noop'; return; }
+		if (params.to == '' || list.contains(params.to)) { list.remove(i); } else { list.set(i, params.to); }
+	`
+	resp, err := global.Es.UpdateByQuery(s.articleIndex).
+		Query(&types.Query{
+			Term: map[string]types.TermQuery{field: {Value: from}},
+		}).
+		Script(&types.InlineScript{
+			Source: script,
+			Params: map[string]json.RawMessage{
+				"field": json.RawMessage(strconv.Quote(field)),
+				"from":  json.RawMessage(strconv.Quote(from)),
+				"to":    json.RawMessage(strconv.Quote(to)),
+			},
+		}).
+		Conflicts(conflicts.Proceed).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("批量修改文章%s失败: %w", field, err)
+	}
+	// 冲突的文章会被跳过，此时调用方不能认为修改已经完成，重试时已修改的文章不会再变化
+	failed := int64(len(resp.Failures))
+	if resp.VersionConflicts != nil {
+		failed += *resp.VersionConflicts
+	}
+	if failed > 0 {
+		return 0, fmt.Errorf("批量修改文章%s未完成，有 %d 篇文章修改失败", field, failed)
+	}
+
+	if resp.Updated == nil {
+		return 0, nil
+	}
+	return *resp.Updated, nil
+}
+
+// slugMaxAttempts 生成不重复 slug 时最多尝试的后缀数量，超出后使用文章id作为后缀
+const slugMaxAttempts = 20
+
+// ArticleSlugAssign 根据标题为文章生成不重复的 slug，标题对应的 slug 变化时原 slug 记入 OldSlugs
+func (s *ArticleService) ArticleSlugAssign(article *Article) error {
+	base := utils.Slugify(article.Title)
+	if base == "" {
+		base = article.ID
+	}
+	// 标题对应的 slug 没有变化。当前 slug 可能是因重复加了序号的 base，
+	// 不能仅凭形如 base-2 判断，标题本身就可能以数字结尾
+	if article.Slug != "" && (article.Slug == base || article.SlugBase == base) {
+		article.SlugBase = base
+		return nil
+	}
+
+	slug := ""
+	for i := 1; i <= slugMaxAttempts && slug == ""; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := s.slugTaken(candidate, article.ID)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			slug = candidate
+		}
+	}
+	if slug == "" {
+		slug = base + "-" + article.ID
+	}
+
+	if article.Slug != "" && !slices.Contains(article.OldSlugs, article.Slug) {
+		article.OldSlugs = append(article.OldSlugs, article.Slug)
+	}
+	// 改回以前的标题时，slug 不再是旧 slug
+	article.OldSlugs = slices.DeleteFunc(article.OldSlugs, func(old string) bool { return old == slug })
+	article.Slug = slug
+	article.SlugBase = base
+	return nil
+}
+
+// slugTaken 检查 slug 是否已被其他文章使用，包括其他文章的旧 slug
+func (s *ArticleService) slugTaken(slug, excludeID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	resp, err := global.Es.Count().
+		Index(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Should: []types.Query{
+					{Term: map[string]types.TermQuery{"slug": {Value: slug}}},
+					{Term: map[string]types.TermQuery{"old_slugs": {Value: slug}}},
+				},
+				MinimumShouldMatch: 1,
+				MustNot:            []types.Query{{Ids: &types.IdsQuery{Values: []string{excludeID}}}},
+			},
+		}).
+		Do(ctx)
+	if err != nil {
+		return false, fmt.Errorf("检查 slug 是否重复失败: %w", err)
+	}
+	return resp.Count > 0, nil
+}
+
+// ArticleGetBySlug 根据 slug 获取文章，命中旧 slug 时 redirected 为 true。
+// 旧 slug 可能已被其他文章使用，此时优先返回当前 slug 匹配的文章
+func (s *ArticleService) ArticleGetBySlug(slug string) (article *Article, redirected bool, err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	slugBoost := float32(2)
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Should: []types.Query{
+					{Term: map[string]types.TermQuery{"slug": {Value: slug, Boost: &slugBoost}}},
+					{Term: map[string]types.TermQuery{"old_slugs": {Value: slug}}},
+				},
+				MinimumShouldMatch: 1,
+			},
+		}).
+		Size(1).
+		Do(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("获取文章失败: %w", err)
+	}
+	if len(resp.Hits.Hits) == 0 {
+		return nil, false, ErrArticleNotFound
+	}
+
+	var result Article
+	if err := json.Unmarshal(resp.Hits.Hits[0].Source_, &result); err != nil {
+		return nil, false, fmt.Errorf("解析文章数据失败: %w", err)
+	}
+	return &result, result.Slug != slug, nil
+}
+
+// ArticleSeriesSet 将文章按顺序设置到系列中，并清除不再属于该系列的文章的系列字段。
+// 有文章未能修改时返回错误，以相同参数重试时已修改的文章不会再变化
+func (s *ArticleService) ArticleSeriesSet(seriesID uint, ids []string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, reindexTimeout)
+	defer cancel()
+
+	seriesIDValue := json.RawMessage(strconv.FormatUint(uint64(seriesID), 10))
+	resp, err := global.Es.UpdateByQuery(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Filter:  []types.Query{{Term: map[string]types.TermQuery{"series_id": {Value: seriesID}}}},
+				MustNot: []types.Query{{Ids: &types.IdsQuery{Values: ids}}},
+			},
+		}).
+		Script(&types.InlineScript{
+			Source: "ctx._source.series_id = 0; ctx._source.series_order = 0",
+		}).
+		Conflicts(conflicts.Proceed).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("清除文章系列失败: %w", err)
+	}
+	// 冲突的文章会被跳过，系列表已经保存，调用方需要重试
+	failed := int64(len(resp.Failures))
+	if resp.VersionConflicts != nil {
+		failed += *resp.VersionConflicts
+	}
+	if failed > 0 {
+		return fmt.Errorf("清除文章系列未完成，有 %d 篇文章修改失败", failed)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	orders := make(map[string]int, len(ids))
+	for i, id := range ids {
+		orders[id] = i
+	}
+	ordersValue, _ := json.Marshal(orders)
+	script := `
+		int order = params.orders[ctx._id];
+		if (ctx._source.series_id == params.series_id && ctx._source.series_order == order) { ctx.op = 'noop'; return; }
+		ctx._source.series_id = params.series_id;
+		ctx._source.series_order = order;
+	`
+	resp, err = global.Es.UpdateByQuery(s.articleIndex).
+		Query(&types.Query{Ids: &types.IdsQuery{Values: ids}}).
+		Script(&types.InlineScript{
+			Source: script,
+			Params: map[string]json.RawMessage{
+				"series_id": seriesIDValue,
+				"orders":    ordersValue,
+			},
+		}).
+		Conflicts(conflicts.Proceed).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置文章系列失败: %w", err)
+	}
+	failed = int64(len(resp.Failures))
+	if resp.VersionConflicts != nil {
+		failed += *resp.VersionConflicts
+	}
+	if failed > 0 {
+		return fmt.Errorf("设置文章系列未完成，有 %d 篇文章修改失败", failed)
+	}
+	return nil
+}
+
+// TermCounts 统计 field 字段中各个取值的文章数，publishedOnly 为 true 时只统计已发布的公开文章，为 false 时包含草稿等所有文章
+func (s *ArticleService) TermCounts(field string, values []string, publishedOnly bool) (map[string]int64, error) {
+	counts := make(map[string]int64, len(values))
+	if len(values) == 0 {
+		return counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	query := &types.Query{MatchAll: &types.MatchAllQuery{}}
+	if publishedOnly {
+		query = &types.Query{Bool: &types.BoolQuery{Filter: []types.Query{publishedQuery(), visibleQuery(0)}}}
+	}
+	size := len(values)
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(query).
+		Size(0).
+		Aggregations(map[string]types.Aggregations{
+			"counts": {Terms: &types.TermsAggregation{Field: &field, Size: &size, Include: values}},
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("统计文章数失败: %w", err)
+	}
+
+	if agg, found := resp.Aggregations["counts"]; found {
+		var termsAgg types.StringTermsAggregate
+		aggBytes, _ := json.Marshal(agg)
+		if err := json.Unmarshal(aggBytes, &termsAgg); err != nil {
+			return nil, fmt.Errorf("解析文章数统计失败: %w", err)
+		}
+		if buckets, ok := termsAgg.Buckets.([]types.StringTermsBucket); ok {
+			for _, bucket := range buckets {
+				counts[fmt.Sprint(bucket.Key)] = bucket.DocCount
+			}
+		}
+	}
+	return counts, nil
+}
+
+// TagCloud 统计已发布文章的标签及文章数，按文章数降序
+func (s *ArticleService) TagCloud(size int) ([]FacetBucket, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	field := "tags"
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(&types.Query{Bool: &types.BoolQuery{Filter: []types.Query{publishedQuery(), visibleQuery(0)}}}).
+		Size(0).
+		Aggregations(map[string]types.Aggregations{
+			"tags": {Terms: &types.TermsAggregation{Field: &field, Size: &size}},
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("统计标签失败: %w", err)
+	}
+
+	tags := make([]FacetBucket, 0)
+	if agg, found := resp.Aggregations["tags"]; found {
+		var termsAgg types.StringTermsAggregate
+		aggBytes, _ := json.Marshal(agg)
+		if err := json.Unmarshal(aggBytes, &termsAgg); err != nil {
+			return nil, fmt.Errorf("解析标签统计失败: %w", err)
+		}
+		if buckets, ok := termsAgg.Buckets.([]types.StringTermsBucket); ok {
+			for _, bucket := range buckets {
+				tags = append(tags, FacetBucket{
+					Key:   fmt.Sprint(bucket.Key),
+					Count: bucket.DocCount,
+				})
+			}
+		}
+	}
+	return tags, nil
+}
+
+// RelatedArticles 获取与指定文章相似的已发布文章，同分类的文章优先
+func (s *ArticleService) RelatedArticles(id string, n int) ([]Article, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	article, err := s.ArticleGet(id)
+	if err != nil {
+		return nil, err
+	}
+
+	minTermFreq, minDocFreq, maxQueryTerms := 1, 1, 25
+	categoryBoost := float32(2)
+	boolQuery := &types.BoolQuery{
+		Must: []types.Query{{
+			MoreLikeThis: &types.MoreLikeThisQuery{
+				Fields:        []string{"title", "abstract", "content"},
+				Like:          []types.Like{types.LikeDocument{Index_: &s.articleIndex, Id_: &id}},
+				MinTermFreq:   &minTermFreq,
+				MinDocFreq:    &minDocFreq,
+				MaxQueryTerms: &maxQueryTerms,
+			},
+		}},
+		Filter:  []types.Query{publishedQuery(), visibleQuery(0)},
+		MustNot: []types.Query{{Ids: &types.IdsQuery{Values: []string{id}}}},
+	}
+	if len(article.Category) > 0 {
+		boolQuery.Should = append(boolQuery.Should, types.Query{
+			Terms: &types.TermsQuery{
+				Boost:      &categoryBoost,
+				TermsQuery: map[string]types.TermsQueryField{"category": article.Category},
+			},
+		})
+	}
+
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(&types.Query{Bool: boolQuery}).
+		Source_(&types.SourceFilter{Excludes: listSourceExcludes}).
+		Size(n).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取相关文章失败: %w", err)
+	}
+
+	articles := make([]Article, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var related Article
+		if err := json.Unmarshal(hit.Source_, &related); err != nil {
+			global.Log.Error("解析文章数据失败",
+				zap.String("error", err.Error()),
+				zap.String("document_id", *hit.Id_),
+			)
+			continue
+		}
+		articles = append(articles, related)
+	}
+
+	return articles, nil
+}
+
+// ArticleSuggest 根据输入前缀返回匹配的已发布文章标题
+func (s *ArticleService) ArticleSuggest(prefix string, size int) ([]ArticleSuggestion, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	// search_as_you_type 推荐的 bool_prefix 查询，最后一个词按前缀匹配
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Must: []types.Query{{
+					MultiMatch: &types.MultiMatchQuery{
+						Query: prefix,
+						Type:  &textquerytype.Boolprefix,
+						Fields: []string{
+							"title.suggest",
+							"title.suggest._2gram",
+							"title.suggest._3gram",
+						},
+					},
+				}},
+				Filter: []types.Query{publishedQuery(), visibleQuery(0)},
+			},
+		}).
+		Source_(&types.SourceFilter{Includes: []string{"id", "title"}}).
+		Size(size).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取输入提示失败: %w", err)
+	}
+
+	suggestions := make([]ArticleSuggestion, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var suggestion ArticleSuggestion
+		if err := json.Unmarshal(hit.Source_, &suggestion); err != nil {
+			global.Log.Error("解析文章数据失败",
+				zap.String("error", err.Error()),
+				zap.String("document_id", *hit.Id_),
+			)
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// ArticleListByIDs 按给定顺序获取多篇已发布的公开文章，不存在、未发布或不公开的文章会被跳过。
+// id 较多时分批查询，不受单次查询数量的限制
+func (s *ArticleService) ArticleListByIDs(ids []string) ([]Article, error) {
+	if len(ids) == 0 {
+		return []Article{}, nil
+	}
+
+	found := make(map[string]Article, len(ids))
+	for i := 0; i < len(ids); i += s.batchSize {
+		batch := ids[i:min(i+s.batchSize, len(ids))]
+		if err := s.articlesFind(batch, found); err != nil {
+			return nil, err
+		}
+	}
+
+	articles := make([]Article, 0, len(found))
+	for _, id := range ids {
+		if article, ok := found[id]; ok {
+			articles = append(articles, article)
+		}
+	}
+	return articles, nil
+}
+
+// articlesFind 查询一批文章中已发布的公开文章，结果按id写入 found
+func (s *ArticleService) articlesFind(ids []string, found map[string]Article) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	resp, err := global.Es.Search().
+		Index(s.articleIndex).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Must:   []types.Query{{Ids: &types.IdsQuery{Values: ids}}},
+				Filter: []types.Query{publishedQuery(), visibleQuery(0)},
+			},
+		}).
+		Source_(&types.SourceFilter{Excludes: listSourceExcludes}).
+		Size(len(ids)).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("批量获取文章失败: %w", err)
+	}
+
+	for _, hit := range resp.Hits.Hits {
+		var article Article
+		if err := json.Unmarshal(hit.Source_, &article); err != nil {
+			global.Log.Error("解析文章数据失败",
+				zap.String("error", err.Error()),
+				zap.String("document_id", *hit.Id_),
+			)
+			continue
+		}
+		found[*hit.Id_] = article
+	}
+	return nil
+}
+
+// ArticleScan 使用 point in time 和 search_after 遍历所有已发布文章，不受 10000 条的分页窗口限制，includes 为空时返回完整文档
+func (s *ArticleService) ArticleScan(includes []string, fn func(articles []Article) error) error {
+	ctx, cancel := context.WithTimeout(s.ctx, reindexTimeout)
+	defer cancel()
+
+	keepAlive := "1m"
+	pit, err := global.Es.OpenPointInTime(s.articleIndex).KeepAlive(keepAlive).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("打开 point in time 失败: %w", err)
+	}
+	pitID := pit.Id
+	defer func() {
+		if _, err := global.Es.ClosePointInTime().Id(pitID).Do(context.Background()); err != nil {
+			global.Log.Error("关闭 point in time 失败", zap.String("error", err.Error()))
+		}
+	}()
+
+	var searchAfter []types.FieldValue
+	for {
+		// 使用 point in time 时不能指定索引，按 _shard_doc 排序最高效
+		req := global.Es.Search().
+			Pit(&types.PointInTimeReference{Id: pitID, KeepAlive: keepAlive}).
+			Query(&types.Query{Bool: &types.BoolQuery{Filter: []types.Query{publishedQuery(), visibleQuery(0)}}}).
+			Sort("_shard_doc").
+			Size(s.batchSize)
+		if includes != nil {
+			req.Source_(&types.SourceFilter{Includes: includes})
+		}
+		if searchAfter != nil {
+			req.SearchAfter(searchAfter...)
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return fmt.Errorf("遍历文章失败: %w", err)
+		}
+		if resp.PitId != nil {
+			pitID = *resp.PitId
+		}
+		if len(resp.Hits.Hits) == 0 {
+			return nil
+		}
+
+		articles := make([]Article, 0, len(resp.Hits.Hits))
+		for _, hit := range resp.Hits.Hits {
+			var article Article
+			if err := json.Unmarshal(hit.Source_, &article); err != nil {
+				global.Log.Error("解析文章数据失败",
+					zap.String("error", err.Error()),
+					zap.String("document_id", *hit.Id_),
+				)
+				continue
+			}
+			article.ID = *hit.Id_
+			articles = append(articles, article)
+		}
+		if err := fn(articles); err != nil {
+			return err
+		}
+
+		searchAfter = resp.Hits.Hits[len(resp.Hits.Hits)-1].Sort
+	}
 }
 
 // ArticleExist 检查文章是否存在
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"blog/global"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry MySQL 唯一索引冲突的错误码
+const mysqlDuplicateEntry = 1062
+
+// ErrRevisionExists 文章的该版本已有修订记录
+var ErrRevisionExists = errors.New("修订记录已存在")
+
+// ArticleRevisionModel 文章修订记录，每次创建或更新文章都会保存一份完整快照
+type ArticleRevisionModel struct {
+	MODEL     `json:","`
+	ArticleID string   `json:"article_id" gorm:"size:32;uniqueIndex:idx_article_version;comment:文章id"`
+	Version   int64    `json:"version" gorm:"uniqueIndex:idx_article_version;comment:版本号"`
+	Title     string   `json:"title" gorm:"comment:文章标题"`
+	Abstract  string   `json:"abstract" gorm:"comment:文章简介"`
+	Content   string   `json:"content,omitempty" gorm:"type:longtext;comment:文章内容"`
+	Category  []string `json:"category" gorm:"serializer:json;comment:文章分类"`
+	Tags      []string `json:"tags" gorm:"serializer:json;comment:文章标签"`
+	CoverID   uint     `json:"cover_id" gorm:"comment:封面id"`
+	CoverURL  string   `json:"cover_url" gorm:"comment:封面"`
+	EditorID  uint     `json:"editor_id" gorm:"comment:编辑者id"`
+}
+
+// RevisionCreate 保存文章当前版本的快照
+func RevisionCreate(article *Article, editorID uint) error {
+	revision := ArticleRevisionModel{
+		ArticleID: article.ID,
+		Version:   article.Version,
+		Title:     article.Title,
+		Abstract:  article.Abstract,
+		Content:   article.Content,
+		Category:  article.Category,
+		Tags:      article.Tags,
+		CoverID:   article.CoverID,
+		CoverURL:  article.CoverURL,
+		EditorID:  editorID,
+	}
+	if err := global.DB.Create(&revision).Error; err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return ErrRevisionExists
+		}
+		return fmt.Errorf("保存文章修订记录失败: %w", err)
+	}
+	return nil
+}
+
+// RevisionGet 获取文章指定版本的修订记录
+func RevisionGet(articleID string, version int64) (*ArticleRevisionModel, error) {
+	var revision ArticleRevisionModel
+	err := global.DB.Where("article_id = ? AND version = ?", articleID, version).
+		Take(&revision).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取文章修订记录失败: %w", err)
+	}
+	return &revision, nil
+}
+
+// RevisionList 分页获取文章的修订记录，不返回正文
+func RevisionList(articleID string, pageInfo PageInfo) ([]ArticleRevisionModel, int64, error) {
+	var (
+		list  []ArticleRevisionModel
+		total int64
+	)
+	query := global.DB.Model(&ArticleRevisionModel{}).Where("article_id = ?", articleID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计修订记录失败: %w", err)
+	}
+
+	offset := (pageInfo.Page - 1) * pageInfo.PageSize
+	err := query.Omit("content").
+		Order("version desc").
+		Limit(pageInfo.PageSize).
+		Offset(offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询修订记录失败: %w", err)
+	}
+	return list, total, nil
+}
+
+// RevisionDelete 删除文章的全部修订记录
+func RevisionDelete(articleIDs []string) error {
+	err := global.DB.Where("article_id IN ?", articleIDs).Delete(&ArticleRevisionModel{}).Error
+	if err != nil {
+		return fmt.Errorf("删除修订记录失败: %w", err)
+	}
+	return nil
+}
+
+// RevisionRemove 删除文章指定版本的修订记录，用于文章写入失败时撤销预先保存的快照。
+// 软删除的记录仍占用唯一索引，必须物理删除，否则同一版本无法重试
+func RevisionRemove(articleID string, version int64) error {
+	err := global.DB.Unscoped().Where("article_id = ? AND version = ?", articleID, version).
+		Delete(&ArticleRevisionModel{}).Error
+	if err != nil {
+		return fmt.Errorf("删除修订记录失败: %w", err)
+	}
+	return nil
+}
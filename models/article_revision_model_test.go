@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArticleReviseRetryAfterFailedWrite(t *testing.T) {
+	testSetup(t)
+	db := testDB(t)
+	es := testEs(t, Article{ID: "1", Title: "旧标题", Version: 3})
+	service := NewArticleService()
+
+	// 第一次写入文章时被其他请求抢先修改
+	es.failUpdates = 1
+	article, err := service.ArticleGet("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article.Title = "新标题"
+	err = service.ArticleRevise(article, 3, 1)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("ArticleRevise() error = %v, want ErrVersionConflict", err)
+	}
+	if db.revision("1", 4) {
+		t.Fatal("文章写入失败后修订记录没有删除")
+	}
+
+	// 以同一版本号重试应当成功
+	article, err = service.ArticleGet("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article.Title = "新标题"
+	if err := service.ArticleRevise(article, 3, 1); err != nil {
+		t.Fatalf("重试 ArticleRevise() error = %v", err)
+	}
+	if article.Version != 4 {
+		t.Errorf("Version = %d, want 4", article.Version)
+	}
+	if !db.revision("1", 4) {
+		t.Error("没有保存版本 4 的修订记录")
+	}
+	if title := es.doc("1", "title"); title != "新标题" {
+		t.Errorf("title = %v, want 新标题", title)
+	}
+}
+
+func TestArticleReviseKeepsConcurrentChanges(t *testing.T) {
+	testSetup(t)
+	db := testDB(t)
+	es := testEs(t, Article{ID: "1", Title: "旧标题", Version: 3})
+	service := NewArticleService()
+
+	article, err := service.ArticleGet("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 读取之后文章被置顶，置顶不改变版本号
+	if err := service.ArticlePinSet("1", true, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	article.Title = "新标题"
+	err = service.ArticleRevise(article, 3, 1)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("ArticleRevise() error = %v, want ErrVersionConflict", err)
+	}
+	if pinned := es.doc("1", "pinned"); pinned != true {
+		t.Errorf("pinned = %v, 置顶被旧数据覆盖", pinned)
+	}
+	if title := es.doc("1", "title"); title != "旧标题" {
+		t.Errorf("title = %v, want 旧标题", title)
+	}
+	if db.revision("1", 4) {
+		t.Error("文章写入失败后修订记录没有删除")
+	}
+}
+
+func TestArticleReviseConcurrentSameVersion(t *testing.T) {
+	testSetup(t)
+	db := testDB(t)
+	es := testEs(t, Article{ID: "1", Title: "旧标题", Version: 3})
+	service := NewArticleService()
+
+	// 另一个基于版本 3 的修改已经保存了版本 4 的修订记录
+	if err := RevisionCreate(&Article{ID: "1", Title: "另一个修改", Version: 4}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := service.ArticleGet("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	article.Title = "新标题"
+	err = service.ArticleRevise(article, 3, 1)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("ArticleRevise() error = %v, want ErrVersionConflict", err)
+	}
+	if !db.revision("1", 4) {
+		t.Error("删除了另一个修改的修订记录")
+	}
+	if title := es.doc("1", "title"); title != "旧标题" {
+		t.Errorf("title = %v, want 旧标题", title)
+	}
+}
@@ -2,19 +2,237 @@
 
 import (
 	"blog/global"
+	"errors"
+
+	"gorm.io/gorm"
 )
 
 type CategoryModel struct {
-	MODEL `json:","`
-	Name  string `json:"name"`
+	MODEL    `json:","`
+	Name     string           `json:"name"`
+	Sort     int              `json:"sort" gorm:"default:0;comment:排序，越小越靠前"`
+	ParentID *uint            `json:"parent_id" gorm:"index;comment:父分类id"`
+	Children []*CategoryModel `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 }
 
+var (
+	ErrParentCategoryNotExist = errors.New("父分类不存在")
+	ErrCategoryCycle          = errors.New("不能移动到分类自身或其子分类下")
+)
+
 // Create 创建分类
 func (c *CategoryModel) Create() error {
 	return global.DB.Create(c).Error
 }
 
-// Delete 删除分类
+// Delete 删除分类，子分类移动到被删除分类的父分类下
 func (c *CategoryModel) Delete() error {
-	return global.DB.Delete(c).Error
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&CategoryModel{}).Where("parent_id = ?", c.ID).Update("parent_id", c.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(c).Error
+	})
+}
+
+// Update 更新分类名称、排序和父分类，返回修改了分类的文章数。
+// 改名时先修改文章中的分类再保存分类，文章修改失败时分类保持原名，可以直接重试
+func (c *CategoryModel) Update(name string, sort int, parentID *uint) (int64, error) {
+	if err := categoryParentCheck(c.ID, parentID); err != nil {
+		return 0, err
+	}
+	var updated int64
+	if name != c.Name {
+		var err error
+		updated, err = NewArticleService().ArticleTermReplace("category", c.Name, name)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return updated, global.DB.Model(c).Updates(map[string]any{"name": name, "sort": sort, "parent_id": parentID}).Error
+}
+
+// CategoryGet 根据id获取分类
+func CategoryGet(id uint) (*CategoryModel, error) {
+	var category CategoryModel
+	if err := global.DB.Take(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CategoryNameExists 检查是否已有其他分类使用该名称
+func CategoryNameExists(name string, excludeID uint) (bool, error) {
+	var count int64
+	err := global.DB.Model(&CategoryModel{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// CategoryEnsure 创建尚不存在的分类，新分类挂在根分类下
+func CategoryEnsure(names []string) error {
+	for _, name := range names {
+		exists, err := CategoryNameExists(name, 0)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := (&CategoryModel{Name: name}).Create(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CategorySort 按给定顺序重新设置分类排序
+func CategorySort(ids []uint) error {
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&CategoryModel{}).Where("id = ?", id).Update("sort", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CategoryMerge 将 source 分类下的文章移动到 target 分类，全部移动成功后才删除 source
+func CategoryMerge(source, target *CategoryModel) error {
+	if err := categoryParentCheck(source.ID, &target.ID); err != nil {
+		return err
+	}
+	_, err := NewArticleService().ArticleTermReplace("category", source.Name, target.Name)
+	if err != nil {
+		return err
+	}
+	return source.Delete()
+}
+
+// categoryAll 获取所有分类，按排序字段排列
+func categoryAll() ([]*CategoryModel, error) {
+	var categories []*CategoryModel
+	if err := global.DB.Order("sort asc, id asc").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// buildCategoryTree 构建分类树，父分类不存在的分类作为根节点
+func buildCategoryTree(allCategories []*CategoryModel) []*CategoryModel {
+	categoryMap := make(map[uint]*CategoryModel)
+	rootCategories := make([]*CategoryModel, 0)
+
+	// 1. 建立映射关系
+	for _, category := range allCategories {
+		categoryMap[category.ID] = category
+	}
+
+	// 2. 构建树形结构
+	for _, category := range allCategories {
+		if category.ParentID == nil {
+			rootCategories = append(rootCategories, category)
+			continue
+		}
+		if parent, exists := categoryMap[*category.ParentID]; exists {
+			parent.Children = append(parent.Children, category)
+		} else {
+			rootCategories = append(rootCategories, category)
+		}
+	}
+
+	return rootCategories
+}
+
+// CategoryTree 获取分类树
+func CategoryTree() ([]*CategoryModel, error) {
+	categories, err := categoryAll()
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// categoryDescendantIDs 获取分类自身及所有子孙分类的id
+func categoryDescendantIDs(categories []*CategoryModel, id uint) map[uint]bool {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	result := map[uint]bool{id: true}
+	queue := []uint{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if !result[child] {
+				result[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return result
+}
+
+// categoryParentCheck 检查父分类存在，且不是分类自身或其子孙分类
+func categoryParentCheck(id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	categories, err := categoryAll()
+	if err != nil {
+		return err
+	}
+
+	exists := false
+	for _, category := range categories {
+		if category.ID == *parentID {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return ErrParentCategoryNotExist
+	}
+	if id != 0 && categoryDescendantIDs(categories, id)[*parentID] {
+		return ErrCategoryCycle
+	}
+	return nil
+}
+
+// CategoryParentCheck 检查新分类的父分类是否存在
+func CategoryParentCheck(parentID *uint) error {
+	return categoryParentCheck(0, parentID)
+}
+
+// CategorySubtreeNames 获取每个分类自身及所有子孙分类的名称，分类不在数据库中时只包含自身。
+// 只查询一次分类表，供一次搜索中的多个分类过滤共用
+func CategorySubtreeNames(names []string) (map[string][]string, error) {
+	categories, err := categoryAll()
+	if err != nil {
+		return nil, err
+	}
+
+	subtrees := make(map[string][]string, len(names))
+	for _, name := range names {
+		subtrees[name] = []string{name}
+		for _, category := range categories {
+			if category.Name != name {
+				continue
+			}
+			ids := categoryDescendantIDs(categories, category.ID)
+			subtree := make([]string, 0, len(ids))
+			for _, c := range categories {
+				if ids[c.ID] {
+					subtree = append(subtree, c.Name)
+				}
+			}
+			subtrees[name] = subtree
+			break
+		}
+	}
+	return subtrees, nil
 }
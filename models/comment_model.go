@@ -96,7 +96,9 @@ func loadSensitiveWordsFromFile() error {
 	return nil
 }
 
-func init() {
+// InitSensitiveWords 初始化敏感词过滤器，在日志初始化之后调用。
+// 未初始化时评论只做 HTML 清理，测试中不需要敏感词文件
+func InitSensitiveWords() {
 	// 敏感词过滤器初始化
 	sensitiveFilter = sensitive.New()
 	// 从文件加载敏感词
@@ -253,6 +255,28 @@ func CommentCreate(comment *CommentModel) error {
 	})
 }
 
+// CommentImport 导入历史评论，保留原有的创建时间，只过滤内容不限制长度
+func CommentImport(comment *CommentModel) error {
+	if strings.TrimSpace(comment.Content) == "" {
+		return ErrEmptyContent
+	}
+	filteredContent, err := filterContent(comment.Content)
+	if err != nil {
+		return err
+	}
+	comment.Content = filteredContent
+
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return fmt.Errorf("创建评论失败: %w", err)
+		}
+		if comment.ParentCommentID != nil {
+			return parentCommentCountUpdate(tx, *comment.ParentCommentID)
+		}
+		return nil
+	})
+}
+
 // CommentDelete 删除评论
 func CommentDelete(commentID uint, articleID string) error {
 	var comment CommentModel
@@ -0,0 +1,21 @@
+package ctypes
+
+// ArticleStatus 文章状态
+type ArticleStatus string
+
+const (
+	StatusDraft     ArticleStatus = "draft"     // 草稿
+	StatusPublished ArticleStatus = "published" // 已发布
+	StatusScheduled ArticleStatus = "scheduled" // 定时发布
+	StatusArchived  ArticleStatus = "archived"  // 已归档
+)
+
+// Visibility 文章可见性
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"   // 公开
+	VisibilityUnlisted Visibility = "unlisted" // 不出现在列表中，知道链接即可访问
+	VisibilityPrivate  Visibility = "private"  // 仅作者和管理员可见
+	VisibilityPassword Visibility = "password" // 输入密码后可见
+)
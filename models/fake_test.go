@@ -0,0 +1,238 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"blog/config"
+	"blog/global"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testSetup 初始化测试需要的全局配置和日志
+func testSetup(t *testing.T) {
+	t.Helper()
+	global.Config = &config.Config{}
+	global.Log = zap.NewNop().Sugar()
+}
+
+// fakeDB 只实现修订记录表的内存数据库，按 MySQL 的行为模拟唯一索引：软删除的记录仍占用索引
+type fakeDB struct {
+	mu     sync.Mutex
+	nextID int64
+	rows   map[string]bool // article_id/version -> 是否已软删除
+}
+
+// testDB 使用 fakeDB 作为 global.DB
+func testDB(t *testing.T) *fakeDB {
+	t.Helper()
+	db := &fakeDB{rows: make(map[string]bool)}
+	gdb, err := gorm.Open(gormmysql.New(gormmysql.Config{
+		Conn:                      sql.OpenDB(fakeConnector{db}),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.DB = gdb
+	return db
+}
+
+// revision 修订记录是否存在，软删除的记录视为不存在
+func (db *fakeDB) revision(articleID string, version int64) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	deleted, ok := db.rows[fmt.Sprintf("%s/%d", articleID, version)]
+	return ok && !deleted
+}
+
+func (db *fakeDB) exec(query string, args []driver.Value) (driver.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	const table = "`article_revision_models`"
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO "+table):
+		columns := strings.Split(query[strings.Index(query, "(")+1:strings.Index(query, ")")], ",")
+		var articleID, version driver.Value
+		for i, column := range columns {
+			switch column {
+			case "`article_id`":
+				articleID = args[i]
+			case "`version`":
+				version = args[i]
+			}
+		}
+		key := fmt.Sprintf("%v/%v", articleID, version)
+		if _, ok := db.rows[key]; ok {
+			return nil, &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '" + key + "' for key 'idx_article_version'"}
+		}
+		db.rows[key] = false
+		db.nextID++
+		return fakeResult(db.nextID), nil
+	case strings.HasPrefix(query, "DELETE FROM "+table):
+		key := fmt.Sprintf("%v/%v", args[len(args)-2], args[len(args)-1])
+		delete(db.rows, key)
+		return fakeResult(0), nil
+	case strings.HasPrefix(query, "UPDATE "+table+" SET `deleted_at`"):
+		key := fmt.Sprintf("%v/%v", args[len(args)-2], args[len(args)-1])
+		if _, ok := db.rows[key]; ok {
+			db.rows[key] = true
+		}
+		return fakeResult(0), nil
+	}
+	return nil, fmt.Errorf("fakeDB 不支持的语句: %s", query)
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+// CheckNamedValue 参数原样传给 fakeDB
+func (c fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.db.exec(s.query, args)
+}
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("fakeDB 不支持查询")
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+// fakeEs 只实现单个文档读取和局部更新的 Elasticsearch，支持 if_seq_no 并发控制
+type fakeEs struct {
+	mu          sync.Mutex
+	docs        map[string]map[string]any
+	seqNo       map[string]int64
+	failUpdates int // 之后的若干次更新直接返回 409，模拟被其他请求抢先修改
+}
+
+// testEs 启动 fakeEs 并作为 global.Es
+func testEs(t *testing.T, articles ...Article) *fakeEs {
+	t.Helper()
+	es := &fakeEs{docs: make(map[string]map[string]any), seqNo: make(map[string]int64)}
+	for _, article := range articles {
+		data, _ := json.Marshal(article)
+		doc := make(map[string]any)
+		_ = json.Unmarshal(data, &doc)
+		es.docs[article.ID] = doc
+	}
+	srv := httptest.NewServer(es)
+	t.Cleanup(srv.Close)
+
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.Es = client
+	return es
+}
+
+// doc 获取文档中的字段
+func (es *fakeEs) doc(id, field string) any {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return es.docs[id][field]
+}
+
+func (es *fakeEs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 {
+		es.error(w, http.StatusBadRequest, "unsupported")
+		return
+	}
+	id := parts[2]
+	doc, ok := es.docs[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"_index": parts[0], "_id": id, "found": false})
+		return
+	}
+
+	switch parts[1] {
+	case "_doc":
+		json.NewEncoder(w).Encode(map[string]any{
+			"_index": parts[0], "_id": id, "_version": es.seqNo[id] + 1, "found": true,
+			"_seq_no": es.seqNo[id], "_primary_term": 1, "_source": doc,
+		})
+	case "_update":
+		if es.failUpdates > 0 {
+			es.failUpdates--
+			es.error(w, http.StatusConflict, "version_conflict_engine_exception")
+			return
+		}
+		if seqNo := r.URL.Query().Get("if_seq_no"); seqNo != "" && seqNo != fmt.Sprint(es.seqNo[id]) {
+			es.error(w, http.StatusConflict, "version_conflict_engine_exception")
+			return
+		}
+		var body struct {
+			Doc map[string]any `json:"doc"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			es.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for k, v := range body.Doc {
+			doc[k] = v
+		}
+		es.seqNo[id]++
+		json.NewEncoder(w).Encode(map[string]any{
+			"_index": parts[0], "_id": id, "_version": es.seqNo[id] + 1, "result": "updated",
+			"_seq_no": es.seqNo[id], "_primary_term": 1,
+			"_shards": map[string]int{"total": 1, "successful": 1, "failed": 0},
+		})
+	default:
+		es.error(w, http.StatusBadRequest, "unsupported")
+	}
+}
+
+func (es *fakeEs) error(w http.ResponseWriter, status int, reason string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":  map[string]any{"type": reason, "reason": reason},
+		"status": status,
+	})
+}
@@ -53,19 +53,15 @@ type UploadResponse struct {
 }
 
 // imageValidate 图片验证函数
-func (im *ImageModel) imageValidate(file *multipart.FileHeader) error {
-	if file == nil {
-		return fmt.Errorf("文件不能为空")
-	}
-
+func (im *ImageModel) imageValidate(fileName string, size int64) error {
 	// 验证文件格式
-	ext := strings.ToLower(filepath.Ext(file.Filename))
+	ext := strings.ToLower(filepath.Ext(fileName))
 	if ext == "" || !utils.InList(ext[1:], WhiteList) {
 		return fmt.Errorf("不支持的文件格式: %s", ext)
 	}
 
 	// 验证文件大小
-	sizeMB := float64(file.Size) / float64(1024*1024)
+	sizeMB := float64(size) / float64(1024*1024)
 	if sizeMB >= float64(global.Config.Upload.Size) {
 		return fmt.Errorf("图片大小超过设定,当前大小为:%.2fMB,设定大小为:%dMB",
 			sizeMB, global.Config.Upload.Size)
@@ -75,29 +71,38 @@ func (im *ImageModel) imageValidate(file *multipart.FileHeader) error {
 
 // Upload 文件上传主函数
 func (im *ImageModel) Upload(file *multipart.FileHeader) (res UploadResponse) {
-	// 1. 验证图片
-	if err := im.imageValidate(file); err != nil {
-		res.Msg = err.Error()
+	if file == nil {
+		res.Msg = "文件不能为空"
 		return
 	}
 
-	// 2. 读取文件内容
+	// 读取文件内容
 	byteData, err := im.readFileContent(file)
 	if err != nil {
 		res.Msg = err.Error()
 		return
 	}
+	return im.UploadBytes(file.Filename, byteData)
+}
+
+// UploadBytes 上传已读入内存的图片，供表单上传和命令行导入共用
+func (im *ImageModel) UploadBytes(fileName string, byteData []byte) (res UploadResponse) {
+	// 1. 验证图片
+	size := int64(len(byteData))
+	if err := im.imageValidate(fileName, size); err != nil {
+		res.Msg = err.Error()
+		return
+	}
 
-	// 3. 计算并检查文件哈希值是否重复
+	// 2. 计算并检查文件哈希值是否重复
 	imageHash := utils.Md5(byteData)
 	if existingImage, exists := im.checkDuplicate(imageHash); exists {
 		return existingImage
 	}
 
-	// 4. 处理文件上传（本地和腾讯云）
+	// 3. 处理文件上传（本地和腾讯云）
 	// 生成本地文件路径
 	basePath := global.Config.Upload.Path
-	fileName := file.Filename
 	localFilePath := filepath.Join("/", basePath, fileName)
 
 	// 确保目录存在
@@ -116,7 +121,7 @@ func (im *ImageModel) Upload(file *multipart.FileHeader) (res UploadResponse) {
 	}
 
 	// 尝试上传到腾讯云
-	cosFilePath, err := im.uploadToTencentCOS(file, byteData)
+	cosFilePath, err := im.uploadToTencentCOS(fileName, byteData)
 	var finalPath string
 	var storageType string
 
@@ -134,8 +139,8 @@ func (im *ImageModel) Upload(file *multipart.FileHeader) (res UploadResponse) {
 		storageType = OnlineStorage
 	}
 
-	// 5. 保存记录到数据库
-	if err := im.imageRecordSave(file, finalPath, storageType, imageHash); err != nil {
+	// 4. 保存记录到数据库
+	if err := im.imageRecordSave(fileName, size, finalPath, storageType, imageHash); err != nil {
 		if storageType == LocalStorage {
 			// 如果是本地存储且数据库保存失败，删除已上传的文件
 			if err := os.Remove(filepath.Join(uploadDir, fileName)); err != nil {
@@ -150,7 +155,7 @@ func (im *ImageModel) Upload(file *multipart.FileHeader) (res UploadResponse) {
 		FileName:  finalPath,
 		IsSuccess: true,
 		Msg:       "上传成功",
-		Size:      file.Size,
+		Size:      size,
 		Hash:      imageHash,
 	}
 }
@@ -183,7 +188,7 @@ func (im *ImageModel) checkDuplicate(hash string) (UploadResponse, bool) {
 }
 
 // uploadToTencentCOS 上传文件到腾讯云COS
-func (im *ImageModel) uploadToTencentCOS(file *multipart.FileHeader, data []byte) (string, error) {
+func (im *ImageModel) uploadToTencentCOS(name string, data []byte) (string, error) {
 	// 获取腾讯云配置
 	cosConfig := global.Config.TencentCos
 
@@ -198,7 +203,7 @@ func (im *ImageModel) uploadToTencentCOS(file *multipart.FileHeader, data []byte
 	})
 
 	// 生成文件名，使用时间戳避免重名
-	ext := filepath.Ext(file.Filename)                          // 获取文件扩展名
+	ext := filepath.Ext(name)                                   // 获取文件扩展名
 	fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext) // 使用时间戳作为文件名
 
 	// 上传对象
@@ -214,12 +219,12 @@ func (im *ImageModel) uploadToTencentCOS(file *multipart.FileHeader, data []byte
 }
 
 // imageRecordSave 保存图片记录到数据库
-func (im *ImageModel) imageRecordSave(file *multipart.FileHeader, filePath, fileType, hash string) error {
+func (im *ImageModel) imageRecordSave(name string, size int64, filePath, fileType, hash string) error {
 	im.Hash = hash
 	im.Path = filePath
-	im.Name = file.Filename
+	im.Name = name
 	im.Type = fileType
-	im.Size = file.Size
+	im.Size = size
 
 	return global.DB.Create(im).Error
 }
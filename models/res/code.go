@@ -76,6 +76,13 @@ const (
 	FileNotFound       ResponseCode = 3302 // 文件不存在
 	FileTooLarge       ResponseCode = 3303 // 文件过大
 	InvalidFileType    ResponseCode = 3304 // 无效的文件类型
+
+	// 文章相关错误 (3400-3499)
+	ArticleVersionConflict ResponseCode = 3400 // 文章版本冲突
+
+	// 分类相关错误 (3500-3599)
+	CategoryInUse  ResponseCode = 3500 // 分类下还有文章
+	CategoryExists ResponseCode = 3501 // 分类已存在
 )
 
 // CodeMsg 错误码消息映射
@@ -148,6 +155,13 @@ var CodeMsg = map[ResponseCode]string{
 	FileNotFound:       "文件不存在",
 	FileTooLarge:       "文件超过大小限制",
 	InvalidFileType:    "不支持的文件类型",
+
+	// 文章相关错误
+	ArticleVersionConflict: "文章已被他人修改，请基于最新版本重新编辑",
+
+	// 分类相关错误
+	CategoryInUse:  "分类下还有文章，请先指定文章要移动到的分类",
+	CategoryExists: "分类已存在，如需合并请使用合并功能",
 }
 
 // GetMsg 获取错误码对应的消息
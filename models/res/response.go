@@ -36,8 +36,13 @@ func SuccessWithMsg(c *gin.Context, data interface{}, msg string) {
 
 // 分页响应
 func SuccessWithPage[T any](c *gin.Context, list T, total int64, page, pageSize int) {
+	Success(c, NewPageData(list, total, page, pageSize))
+}
+
+// 构建分页数据，用于需要在分页数据之外附带其他字段的响应
+func NewPageData[T any](list T, total int64, page, pageSize int) PageData[T] {
 	totalPages := (int(total) + pageSize - 1) / pageSize
-	pageData := PageData[T]{
+	return PageData[T]{
 		List:       list,
 		Total:      total,
 		Page:       page,
@@ -45,7 +50,6 @@ func SuccessWithPage[T any](c *gin.Context, list T, total int64, page, pageSize
 		TotalPages: totalPages,
 		HasMore:    page < totalPages,
 	}
-	Success(c, pageData)
 }
 
 // 错误响应
@@ -53,6 +57,11 @@ func Error(c *gin.Context, code ResponseCode, msg string) {
 	response(c, http.StatusOK, code, msg, nil)
 }
 
+// 错误响应带数据
+func ErrorWithData(c *gin.Context, code ResponseCode, msg string, data interface{}) {
+	response(c, http.StatusOK, code, msg, data)
+}
+
 // HTTP错误响应
 func HttpError(c *gin.Context, httpStatus int, code ResponseCode, msg string) {
 	response(c, httpStatus, code, msg, nil)
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"blog/global"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// SeriesModel 文章系列，如多篇连载的教程，ArticleIDs 的顺序即阅读顺序
+type SeriesModel struct {
+	MODEL      `json:","`
+	Title      string   `json:"title" gorm:"size:50;comment:系列标题"`
+	Abstract   string   `json:"abstract" gorm:"comment:系列简介"`
+	ArticleIDs []string `json:"article_ids" gorm:"serializer:json;comment:按顺序排列的文章id"`
+}
+
+// SeriesNav 文章在系列中的位置，用于文章详情中的上一篇、下一篇导航
+type SeriesNav struct {
+	ID    uint           `json:"id"`
+	Title string         `json:"title"`
+	Index int            `json:"index"` // 从 0 开始，只计算已发布的文章
+	Total int            `json:"total"`
+	Prev  *SeriesArticle `json:"prev"`
+	Next  *SeriesArticle `json:"next"`
+}
+
+// SeriesArticle 系列导航中的文章
+type SeriesArticle struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+var ErrSeriesArticleNotExist = errors.New("系列中的文章不存在")
+
+// Create 创建系列
+func (s *SeriesModel) Create() error {
+	return global.DB.Create(s).Error
+}
+
+// Update 更新系列标题和简介
+func (s *SeriesModel) Update(title, abstract string) error {
+	return global.DB.Model(s).Updates(map[string]any{"title": title, "abstract": abstract}).Error
+}
+
+// Delete 删除系列，并清除文章的系列字段
+func (s *SeriesModel) Delete() error {
+	if err := NewArticleService().ArticleSeriesSet(s.ID, nil); err != nil {
+		return err
+	}
+	return global.DB.Delete(s).Error
+}
+
+// SeriesGet 根据id获取系列
+func SeriesGet(id uint) (*SeriesModel, error) {
+	var series SeriesModel
+	if err := global.DB.Take(&series, id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// SeriesArticlesSet 按顺序设置系列中的文章，一篇文章只能属于一个系列，会从原来的系列中移除
+func (s *SeriesModel) SeriesArticlesSet(ids []string) error {
+	articleService := NewArticleService()
+	for _, id := range ids {
+		exists, err := articleService.ArticleExist(id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrSeriesArticleNotExist, id)
+		}
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var others []SeriesModel
+		if err := tx.Where("id <> ?", s.ID).Find(&others).Error; err != nil {
+			return err
+		}
+		for _, other := range others {
+			remaining := slices.DeleteFunc(slices.Clone(other.ArticleIDs), func(id string) bool {
+				return slices.Contains(ids, id)
+			})
+			if len(remaining) == len(other.ArticleIDs) {
+				continue
+			}
+			if err := tx.Model(&other).Select("article_ids").Updates(&SeriesModel{ArticleIDs: remaining}).Error; err != nil {
+				return err
+			}
+		}
+		s.ArticleIDs = ids
+		return tx.Model(s).Select("article_ids").Updates(&SeriesModel{ArticleIDs: ids}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return articleService.ArticleSeriesSet(s.ID, ids)
+}
+
+// SeriesRemoveArticles 从所有系列中移除已删除的文章
+func SeriesRemoveArticles(ids []string) error {
+	var series []SeriesModel
+	if err := global.DB.Find(&series).Error; err != nil {
+		return err
+	}
+	for _, s := range series {
+		remaining := slices.DeleteFunc(slices.Clone(s.ArticleIDs), func(id string) bool {
+			return slices.Contains(ids, id)
+		})
+		if len(remaining) == len(s.ArticleIDs) {
+			continue
+		}
+		if err := global.DB.Model(&s).Select("article_ids").Updates(&SeriesModel{ArticleIDs: remaining}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeriesNavGet 获取文章在系列中的导航信息，跳过未发布和不公开的文章
+func SeriesNavGet(article *Article) (*SeriesNav, error) {
+	if article.SeriesID == 0 {
+		return nil, nil
+	}
+	series, err := SeriesGet(article.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := NewArticleService().ArticleListByIDs(series.ArticleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	nav := &SeriesNav{ID: series.ID, Title: series.Title, Index: -1, Total: len(articles)}
+	for i, a := range articles {
+		if a.ID != article.ID {
+			continue
+		}
+		nav.Index = i
+		if i > 0 {
+			nav.Prev = &SeriesArticle{ID: articles[i-1].ID, Title: articles[i-1].Title}
+		}
+		if i < len(articles)-1 {
+			nav.Next = &SeriesArticle{ID: articles[i+1].ID, Title: articles[i+1].Title}
+		}
+		break
+	}
+	return nav, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"blog/global"
+
+	"gorm.io/gorm/clause"
+)
+
+// TagModel 文章标签，与分类不同，一篇文章可以有多个标签
+type TagModel struct {
+	MODEL `json:","`
+	Name  string `json:"name" gorm:"size:20;uniqueIndex;comment:标签名"`
+}
+
+// Create 创建标签
+func (t *TagModel) Create() error {
+	return global.DB.Create(t).Error
+}
+
+// Delete 删除标签，标签名唯一，直接物理删除以便之后重新创建同名标签
+func (t *TagModel) Delete() error {
+	return global.DB.Unscoped().Delete(t).Error
+}
+
+// Rename 修改标签名
+func (t *TagModel) Rename(name string) error {
+	return global.DB.Model(t).Update("name", name).Error
+}
+
+// TagNameExist 检查除 id 以外是否已有同名标签
+func TagNameExist(name string, id uint) (bool, error) {
+	var count int64
+	err := global.DB.Model(&TagModel{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error
+	return count > 0, err
+}
+
+// TagGet 根据id获取标签
+func TagGet(id uint) (*TagModel, error) {
+	var tag TagModel
+	if err := global.DB.Take(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// TagEnsure 创建文章中用到但还不存在的标签
+func TagEnsure(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]TagModel, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, TagModel{Name: name})
+	}
+	return global.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+}
@@ -8,10 +8,27 @@ import (
 func (router RouterGroup) ArticleRouter() {
 	articleApi := api.AppGroupApp.ArticleApi
 	articleRouter := router.Group("article")
-	articleRouter.GET(":id", articleApi.ArticleDetail)
+	articleRouter.GET("suggest", articleApi.ArticleSuggest)
+	articleRouter.GET("hot", articleApi.ArticleHot)
+	articleRouter.GET("collects", middleware.JwtAuth(), articleApi.ArticleCollectList)
+	articleRouter.GET("slug/:slug", middleware.JwtOptional(), articleApi.ArticleSlug)
+	articleRouter.GET(":id", middleware.JwtOptional(), articleApi.ArticleDetail)
 	articleRouter.POST("", middleware.JwtAdmin(), articleApi.ArticleCreate)
-	articleRouter.POST("list", articleApi.ArticleList)
+	articleRouter.POST("list", middleware.JwtOptional(), articleApi.ArticleList)
 	articleRouter.POST("delete", middleware.JwtAdmin(), articleApi.ArticleDelete)
 	articleRouter.PUT("", middleware.JwtAdmin(), articleApi.ArticleUpdate)
+	articleRouter.PUT(":id/pin", middleware.JwtAdmin(), articleApi.ArticlePin)
+	articleRouter.PUT(":id/feature", middleware.JwtAdmin(), articleApi.ArticleFeature)
 	articleRouter.GET("data", middleware.JwtAdmin(), articleApi.GetArticleData)
+	articleRouter.GET(":id/related", articleApi.ArticleRelated)
+	articleRouter.GET(":id/export", middleware.JwtOptional(), articleApi.ArticleExport)
+	articleRouter.POST(":id/unlock", articleApi.ArticleUnlock)
+	articleRouter.POST(":id/digg", middleware.JwtAuth(), articleApi.ArticleDigg)
+	articleRouter.DELETE(":id/digg", middleware.JwtAuth(), articleApi.ArticleDiggCancel)
+	articleRouter.POST(":id/collect", middleware.JwtAuth(), articleApi.ArticleCollect)
+	articleRouter.DELETE(":id/collect", middleware.JwtAuth(), articleApi.ArticleCollectCancel)
+	articleRouter.GET(":id/revisions", middleware.JwtAdmin(), articleApi.ArticleRevisionList)
+	articleRouter.GET(":id/revisions/diff", middleware.JwtAdmin(), articleApi.ArticleRevisionDiff)
+	articleRouter.GET(":id/revisions/:version", middleware.JwtAdmin(), articleApi.ArticleRevisionDetail)
+	articleRouter.POST(":id/revisions/:version/restore", middleware.JwtAdmin(), articleApi.ArticleRevisionRestore)
 }
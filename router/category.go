@@ -9,6 +9,10 @@ func (r *RouterGroup) CategoryRouter() {
 	categoryRouter := r.Group("category")
 	categoryApi := api.AppGroupApp.CategoryApi
 	categoryRouter.POST("", middleware.JwtAdmin(), categoryApi.CategoryCreate)
+	categoryRouter.PUT("sort", middleware.JwtAdmin(), categoryApi.CategorySort)
+	categoryRouter.PUT(":id", middleware.JwtAdmin(), categoryApi.CategoryUpdate)
+	categoryRouter.POST(":id/merge", middleware.JwtAdmin(), categoryApi.CategoryMerge)
 	categoryRouter.DELETE(":id", middleware.JwtAdmin(), categoryApi.CategoryDelete)
 	categoryRouter.GET("list", categoryApi.CategoryList)
+	categoryRouter.GET("tree", categoryApi.CategoryTree)
 }
@@ -33,10 +33,16 @@ func InitRouter() *gin.Engine {
 	routerGroupApp.ArticleRouter()
 	routerGroupApp.CommentRouter()
 	routerGroupApp.CategoryRouter()
+	routerGroupApp.TagRouter()
+	routerGroupApp.SeriesRouter()
 	routerGroupApp.FriendLinkRouter()
 	routerGroupApp.DataRouter()
 	routerGroupApp.VisitRouter()
 	routerGroupApp.LogRouter()
 	routerGroupApp.ChatRouter()
+	routerGroupApp.FeedRouter()
+	// 根路径下的路由
+	rootRouterGroup := RouterGroup{&router.RouterGroup}
+	rootRouterGroup.SitemapRouter()
 	return router
 }
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"blog/api"
+)
+
+func (router RouterGroup) FeedRouter() {
+	feedApi := api.AppGroupApp.FeedApi
+	router.GET("feed.xml", feedApi.FeedRSS)
+	router.GET("atom.xml", feedApi.FeedAtom)
+}
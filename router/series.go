@@ -0,0 +1,17 @@
+package router
+
+import (
+	"blog/api"
+	"blog/middleware"
+)
+
+func (r *RouterGroup) SeriesRouter() {
+	seriesRouter := r.Group("series")
+	seriesApi := api.AppGroupApp.SeriesApi
+	seriesRouter.POST("", middleware.JwtAdmin(), seriesApi.SeriesCreate)
+	seriesRouter.PUT(":id", middleware.JwtAdmin(), seriesApi.SeriesUpdate)
+	seriesRouter.PUT(":id/articles", middleware.JwtAdmin(), seriesApi.SeriesArticles)
+	seriesRouter.DELETE(":id", middleware.JwtAdmin(), seriesApi.SeriesDelete)
+	seriesRouter.GET("list", seriesApi.SeriesList)
+	seriesRouter.GET(":id", seriesApi.SeriesDetail)
+}
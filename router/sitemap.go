@@ -0,0 +1,12 @@
+package router
+
+import (
+	"blog/api"
+)
+
+// SitemapRouter sitemap 只能包含同一路径下的 URL，因此挂在根路径而不是 api 下
+func (router RouterGroup) SitemapRouter() {
+	sitemapApi := api.AppGroupApp.SitemapApi
+	router.GET("sitemap.xml", sitemapApi.SitemapIndex)
+	router.GET("sitemaps/:name", sitemapApi.SitemapPart)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"blog/api"
+	"blog/middleware"
+)
+
+func (r *RouterGroup) TagRouter() {
+	tagRouter := r.Group("tag")
+	tagApi := api.AppGroupApp.TagApi
+	tagRouter.POST("", middleware.JwtAdmin(), tagApi.TagCreate)
+	tagRouter.PUT(":id", middleware.JwtAdmin(), tagApi.TagUpdate)
+	tagRouter.DELETE(":id", middleware.JwtAdmin(), tagApi.TagDelete)
+	tagRouter.GET("list", tagApi.TagList)
+	tagRouter.GET("cloud", tagApi.TagCloud)
+}
@@ -4,6 +4,7 @@ import (
 	"blog/global"
 	"blog/models"
 	"blog/service/redis_ser"
+	"blog/service/sitemap_ser"
 	"context"
 	"strings"
 	"time"
@@ -28,6 +29,10 @@ func SyncArticleData() {
 		key := iter.Val()
 		// 从键中提取文章ID
 		articleID := strings.TrimPrefix(key, redis_ser.ArticlePrefix)
+		// 跳过同一前缀下的其他数据，如浏览记录、相关文章缓存
+		if strings.Contains(articleID, ":") {
+			continue
+		}
 		global.Log.Info("获取文章ID成功",
 			zap.String("article_id", articleID),
 		)
@@ -58,20 +63,24 @@ func SyncArticleData() {
 			zap.Any("article", article),
 		)
 
-		// 更新文章统计数据
-		needsUpdate := false
+		// 更新文章统计数据，只写入变化的字段，避免覆盖文章内容和版本号
+		changed := make(map[string]uint)
 		if lookCount, exists := stats[redis_ser.FieldLookCount]; exists && uint(lookCount) != article.LookCount {
-			article.LookCount = uint(lookCount)
-			needsUpdate = true
+			changed[redis_ser.FieldLookCount] = uint(lookCount)
 		}
 		if commentCount, exists := stats[redis_ser.FieldCommentCount]; exists && uint(commentCount) != article.CommentCount {
-			article.CommentCount = uint(commentCount)
-			needsUpdate = true
+			changed[redis_ser.FieldCommentCount] = uint(commentCount)
+		}
+		if diggCount, exists := stats[redis_ser.FieldDiggCount]; exists && uint(diggCount) != article.DiggCount {
+			changed[redis_ser.FieldDiggCount] = uint(diggCount)
+		}
+		if collectsCount, exists := stats[redis_ser.FieldCollectsCount]; exists && uint(collectsCount) != article.CollectsCount {
+			changed[redis_ser.FieldCollectsCount] = uint(collectsCount)
 		}
 
 		// 如果有数据需要更新，则更新ES中的文章数据
-		if needsUpdate {
-			if err := articleService.ArticleUpdate(article); err != nil {
+		if len(changed) > 0 {
+			if err := articleService.ArticleStatsUpdate(articleID, changed); err != nil {
 				global.Log.Error("更新ES文章数据失败",
 					zap.String("article_id", articleID),
 					zap.String("error", err.Error()),
@@ -93,3 +102,56 @@ func SyncArticleData() {
 	}
 	global.Log.Info("同步文章数据完成")
 }
+
+// PublishScheduledArticles 发布到期的定时文章
+func PublishScheduledArticles() {
+	count, err := models.NewArticleService().ScheduledArticlePublish()
+	if err != nil {
+		global.Log.Error("发布定时文章失败", zap.String("error", err.Error()))
+		return
+	}
+	if count > 0 {
+		global.Log.Info("发布定时文章成功", zap.Int64("count", count))
+		sitemap_ser.MarkDirty()
+	}
+}
+
+// RefreshHotArticles 刷新热度排行并删除过期的热度分桶
+func RefreshHotArticles() {
+	for window := range redis_ser.HotWindows {
+		if err := redis_ser.RefreshHotRank(window); err != nil {
+			global.Log.Error("刷新热度排行失败",
+				zap.String("window", window),
+				zap.String("error", err.Error()),
+			)
+		}
+	}
+
+	count, err := redis_ser.ExpireHotBuckets()
+	if err != nil {
+		global.Log.Error("删除过期热度分桶失败", zap.String("error", err.Error()))
+		return
+	}
+	if count > 0 {
+		global.Log.Info("删除过期热度分桶成功", zap.Int("count", count))
+	}
+}
+
+// RefreshSitemap 文章有变化时重新生成 sitemap
+func RefreshSitemap() {
+	if err := sitemap_ser.RefreshIfNeeded(); err != nil {
+		global.Log.Error("生成 sitemap 失败", zap.String("error", err.Error()))
+	}
+}
+
+// UnpinExpiredArticles 取消已到期的置顶
+func UnpinExpiredArticles() {
+	count, err := models.NewArticleService().ExpiredPinUnset()
+	if err != nil {
+		global.Log.Error("取消到期置顶失败", zap.String("error", err.Error()))
+		return
+	}
+	if count > 0 {
+		global.Log.Info("取消到期置顶成功", zap.Int64("count", count))
+	}
+}
@@ -27,6 +27,10 @@ func CornInit() {
 	timezone, _ := time.LoadLocation("Asia/Shanghai")
 	Cron := cron.New(cron.WithSeconds(), cron.WithLocation(timezone))
 	Cron.AddFunc("0 */1 * * * *", SyncArticleData)
+	Cron.AddFunc("30 */1 * * * *", PublishScheduledArticles)
+	Cron.AddFunc("45 */1 * * * *", UnpinExpiredArticles)
+	Cron.AddFunc("0 */10 * * * *", RefreshHotArticles)
+	Cron.AddFunc("0 */5 * * * *", RefreshSitemap)
 	//Cron.AddFunc("* * * * * *", SyncArticleData)
 	Cron.Start()
 }
@@ -0,0 +1,240 @@
+package export_ser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"blog/global"
+	"blog/models"
+	"blog/utils"
+
+	"github.com/PuerkitoBio/goquery"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"go.uber.org/zap"
+)
+
+const (
+	maxAssetSize      = 20 << 20 // 单张图片的大小上限
+	assetFetchTimeout = time.Second * 10
+)
+
+// assetMediaTypes 可以打包的图片格式，均为 EPUB 核心媒体类型
+var assetMediaTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+}
+
+var assetClient = &http.Client{Timeout: assetFetchTimeout}
+
+// Book 由一篇或多篇文章组成的离线文档，每篇文章为一章
+type Book struct {
+	Title    string
+	Author   string
+	Modified time.Time
+	Chapters []Chapter
+	Cover    *asset // 为空表示没有封面
+
+	assets []*asset
+	bySrc  map[string]*asset // 原始地址 -> 已打包的图片
+}
+
+// Chapter 章节，HTML 中已打包的图片地址替换为 asset.Name
+type Chapter struct {
+	Article models.Article
+	HTML    string
+}
+
+// asset 打包进文档的图片
+type asset struct {
+	ID        string
+	Name      string // 在文档中的相对路径，如 images/1.png
+	MediaType string
+	Data      []byte
+}
+
+// dataURI 以 data URI 形式内联的图片
+func (a *asset) dataURI() string {
+	return "data:" + a.MediaType + ";base64," + base64.StdEncoding.EncodeToString(a.Data)
+}
+
+// NewBook 渲染文章正文并收集封面和正文中引用的图片，无法打包的图片保留原地址
+func NewBook(title string, articles []models.Article) (*Book, error) {
+	book := &Book{
+		Title: title,
+		bySrc: make(map[string]*asset),
+	}
+	for _, article := range articles {
+		content, err := utils.ConvertMarkdownToHTML(article.Content)
+		if err != nil {
+			return nil, fmt.Errorf("渲染文章 %s 失败: %w", article.ID, err)
+		}
+		content, err = rewriteImages(content, func(img *goquery.Selection, src string) {
+			if a := book.assetAdd(src); a != nil {
+				img.SetAttr("src", a.Name)
+			} else if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+				// 离线文档中站内相对地址无法访问，补全为站点地址
+				img.SetAttr("src", strings.TrimRight(global.Config.Site.URL, "/")+src)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		book.Chapters = append(book.Chapters, Chapter{Article: article, HTML: content})
+		if book.Cover == nil && article.CoverURL != "" {
+			book.Cover = book.assetAdd(article.CoverURL)
+		}
+		if article.UserName != "" && book.Author == "" {
+			book.Author = article.UserName
+		}
+		if updated := time.Time(article.UpdatedAt); updated.After(book.Modified) {
+			book.Modified = updated
+		}
+	}
+	if book.Author == "" {
+		book.Author = global.Config.Site.Author
+	}
+	if book.Modified.IsZero() {
+		book.Modified = time.Now()
+	}
+	return book, nil
+}
+
+// assetAdd 读取图片并加入文档，同一地址只打包一次，读取失败返回 nil
+func (b *Book) assetAdd(src string) *asset {
+	if src == "" {
+		return nil
+	}
+	if a, ok := b.bySrc[src]; ok {
+		return a
+	}
+
+	ext := strings.ToLower(path.Ext(strings.SplitN(src, "?", 2)[0]))
+	mediaType, ok := assetMediaTypes[ext]
+	if !ok {
+		return nil
+	}
+	data, err := assetRead(src)
+	if err != nil {
+		global.Log.Warn("读取图片失败，保留原地址", zap.String("src", src), zap.String("error", err.Error()))
+		b.bySrc[src] = nil
+		return nil
+	}
+
+	n := len(b.assets) + 1
+	a := &asset{
+		ID:        fmt.Sprintf("image-%d", n),
+		Name:      fmt.Sprintf("images/%d%s", n, ext),
+		MediaType: mediaType,
+		Data:      data,
+	}
+	b.assets = append(b.assets, a)
+	b.bySrc[src] = a
+	return a
+}
+
+// assetRead 读取图片内容。本地图片只允许读取上传目录下的文件，
+// 远程图片只下载图库中登记过的地址，避免导出接口被用来读取任意文件或请求任意地址
+func assetRead(src string) ([]byte, error) {
+	if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+		name := strings.TrimPrefix(path.Clean(src), "/")
+		uploadDir := filepath.Clean(global.Config.Upload.Path)
+		if !strings.HasPrefix(filepath.FromSlash(name), uploadDir+string(filepath.Separator)) {
+			return nil, fmt.Errorf("不在上传目录中")
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > maxAssetSize {
+			return nil, fmt.Errorf("图片超过 %dMB", maxAssetSize>>20)
+		}
+		return os.ReadFile(name)
+	}
+
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return nil, fmt.Errorf("不支持的地址")
+	}
+	var count int64
+	err := global.DB.Model(&models.ImageModel{}).Where("path = ?", src).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("不是图库中的图片")
+	}
+
+	resp, err := assetClient.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("图片超过 %dMB", maxAssetSize>>20)
+	}
+	return data, nil
+}
+
+// rewriteImages 遍历 HTML 片段中的图片进行修改。
+// 返回的 HTML 由 x/net/html 序列化，空元素自闭合，可以直接作为 XHTML 使用
+func rewriteImages(content string, fn func(img *goquery.Selection, src string)) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	doc.Find("img").Each(func(_ int, img *goquery.Selection) {
+		src, _ := img.Attr("src")
+		fn(img, src)
+	})
+	return doc.Find("body").Html()
+}
+
+// assetsByName 已打包图片，文档内路径 -> 图片
+func (b *Book) assetsByName() map[string]*asset {
+	assets := make(map[string]*asset, len(b.assets))
+	for _, a := range b.assets {
+		assets[a.Name] = a
+	}
+	return assets
+}
+
+// styleSheet 导出文档使用的样式，包含与 Markdown 渲染器对应的代码高亮样式
+func styleSheet() string {
+	var buf bytes.Buffer
+	buf.WriteString(baseStyle)
+	err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, styles.Get("github"))
+	if err != nil {
+		global.Log.Warn("生成代码高亮样式失败", zap.String("error", err.Error()))
+	}
+	return buf.String()
+}
+
+const baseStyle = `body{font-family:sans-serif;line-height:1.7;color:#222}
+h1.title{margin-bottom:.2em}
+.meta{color:#888;font-size:.9em}
+pre{background:#f6f8fa;padding:1em;overflow:auto;white-space:pre-wrap}
+img{max-width:100%}
+table{border-collapse:collapse}
+th,td{border:1px solid #ddd;padding:.3em .6em}
+blockquote{margin-left:0;padding-left:1em;border-left:4px solid #ddd;color:#555}
+`
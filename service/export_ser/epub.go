@@ -0,0 +1,249 @@
+package export_ser
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+
+	"blog/global"
+	"blog/utils"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const epubMimeType = "application/epub+zip"
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+// epubTemplates EPUB 3 的包文件、导航文档和章节，同时生成 toc.ncx 兼容只支持 EPUB 2 的阅读器
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{
+	"xml": html.EscapeString,
+}).Parse(`
+{{define "head"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="zh-CN" lang="zh-CN">
+<head>
+<meta charset="utf-8"/>
+<title>{{xml .}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+{{end}}
+
+{{define "content.opf"}}<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="zh-CN">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">urn:uuid:{{.UUID}}</dc:identifier>
+<dc:title>{{xml .Title}}</dc:title>
+{{with .Author}}<dc:creator>{{xml .}}</dc:creator>
+{{end}}<dc:language>zh-CN</dc:language>
+{{with .Publisher}}<dc:publisher>{{xml .}}</dc:publisher>
+{{end}}<meta property="dcterms:modified">{{.Modified}}</meta>
+{{with .Cover}}<meta name="cover" content="{{.ID}}"/>
+{{end}}</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+<item id="style" href="style.css" media-type="text/css"/>
+{{if .Cover}}<item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+{{end}}{{range .Chapters}}<item id="{{.ID}}" href="{{.ID}}.xhtml" media-type="application/xhtml+xml"/>
+{{end}}{{range .Assets}}<item id="{{.ID}}" href="{{.Name}}" media-type="{{.MediaType}}"{{if and $.Cover (eq .ID $.Cover.ID)}} properties="cover-image"{{end}}/>
+{{end}}</manifest>
+<spine toc="ncx">
+{{if .Cover}}<itemref idref="cover"/>
+{{end}}<itemref idref="nav"/>
+{{range .Chapters}}<itemref idref="{{.ID}}"/>
+{{end}}</spine>
+</package>
+{{end}}
+
+{{define "toc.ncx"}}<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head>
+<meta name="dtb:uid" content="urn:uuid:{{.UUID}}"/>
+</head>
+<docTitle><text>{{xml .Title}}</text></docTitle>
+<navMap>
+{{range .Chapters}}<navPoint id="nav-{{.ID}}" playOrder="{{.Order}}"><navLabel><text>{{xml .Title}}</text></navLabel><content src="{{.ID}}.xhtml"/></navPoint>
+{{end}}</navMap>
+</ncx>
+{{end}}
+
+{{define "nav.xhtml"}}{{template "head" "目录"}}<body>
+<nav epub:type="toc" id="toc">
+<h1>{{xml .Title}}</h1>
+<ol>
+{{range .Chapters}}<li><a href="{{.ID}}.xhtml">{{xml .Title}}</a></li>
+{{end}}</ol>
+</nav>
+</body>
+</html>
+{{end}}
+
+{{define "cover.xhtml"}}{{template "head" "封面"}}<body>
+<section epub:type="cover"><img src="{{.Cover.Name}}" alt="封面"/></section>
+</body>
+</html>
+{{end}}
+
+{{define "chapter"}}{{template "head" .Title}}<body>
+<section epub:type="chapter">
+<h1 class="title">{{xml .Title}}</h1>
+<p class="meta">{{.Date}}</p>
+{{.Content}}
+</section>
+</body>
+</html>
+{{end}}
+`))
+
+type epubChapter struct {
+	ID      string
+	Order   int
+	Title   string
+	Date    string
+	Content string
+}
+
+// epubFile 压缩包中的文件
+type epubFile struct {
+	name string
+	data []byte
+}
+
+type epubPackage struct {
+	UUID      string
+	Title     string
+	Author    string
+	Publisher string
+	Modified  string
+	Cover     *asset
+	Assets    []*asset
+	Chapters  []epubChapter
+}
+
+// EPUB 导出为 EPUB 3 电子书。图片打包在 images 目录下，
+// 未能打包的图片替换为指向原地址的链接，因为 EPUB 不允许引用远程图片
+func (b *Book) EPUB() ([]byte, error) {
+	assets := b.assetsByName()
+	ids := make([]string, 0, len(b.Chapters))
+	pkg := epubPackage{
+		Title:     b.Title,
+		Author:    b.Author,
+		Publisher: global.Config.Site.Title,
+		Modified:  b.Modified.UTC().Format(time.RFC3339),
+		Cover:     b.Cover,
+		Assets:    b.assets,
+	}
+	for i, chapter := range b.Chapters {
+		content, err := rewriteImages(chapter.HTML, func(img *goquery.Selection, src string) {
+			if _, ok := assets[src]; ok {
+				return
+			}
+			text := img.AttrOr("alt", "")
+			if text == "" {
+				text = src
+			}
+			img.ReplaceWithHtml(`<a href="` + html.EscapeString(src) + `">` + html.EscapeString(text) + `</a>`)
+		})
+		if err != nil {
+			return nil, err
+		}
+		pkg.Chapters = append(pkg.Chapters, epubChapter{
+			ID:      fmt.Sprintf("chapter-%d", i+1),
+			Order:   i + 1,
+			Title:   chapter.Article.Title,
+			Date:    time.Time(chapter.Article.CreatedAt).Format(time.DateOnly),
+			Content: content,
+		})
+		ids = append(ids, chapter.Article.ID)
+	}
+	// 同一组文章导出的标识保持不变，阅读器可以识别为同一本书
+	pkg.UUID = uuidFormat(utils.Md5([]byte(strings.Join(ids, ","))))
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	// mimetype 必须是第一个文件，且不压缩、没有扩展字段
+	if err := epubMimeTypeWrite(w); err != nil {
+		return nil, err
+	}
+
+	files := []epubFile{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/style.css", []byte(styleSheet())},
+	}
+	for _, name := range []string{"content.opf", "toc.ncx", "nav.xhtml", "cover.xhtml"} {
+		if name == "cover.xhtml" && pkg.Cover == nil {
+			continue
+		}
+		data, err := epubRender(name, pkg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, epubFile{"OEBPS/" + name, data})
+	}
+	for _, chapter := range pkg.Chapters {
+		data, err := epubRender("chapter", chapter)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, epubFile{"OEBPS/" + chapter.ID + ".xhtml", data})
+	}
+	for _, a := range b.assets {
+		files = append(files, epubFile{"OEBPS/" + a.Name, a.Data})
+	}
+
+	for _, file := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: b.Modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// epubMimeTypeWrite 写入不压缩的 mimetype 文件，使用 CreateRaw 避免写入数据描述符
+func epubMimeTypeWrite(w *zip.Writer) error {
+	data := []byte(epubMimeType)
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(data)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func epubRender(name string, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := epubTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("生成 %s 失败: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// uuidFormat 把 32 位十六进制字符串格式化为 UUID
+func uuidFormat(hex string) string {
+	return hex[0:8] + "-" + hex[8:12] + "-" + hex[12:16] + "-" + hex[16:20] + "-" + hex[20:32]
+}
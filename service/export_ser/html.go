@@ -0,0 +1,86 @@
+package export_ser
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// htmlTemplate 单文件 HTML，图片以 data URI 内联，多篇文章时附带目录
+var htmlTemplate = template.Must(template.New("book").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>body{max-width:760px;margin:0 auto;padding:0 1em}
+{{.Style}}</style>
+</head>
+<body>
+{{with .Cover}}<p><img src="{{.}}" alt="封面"></p>{{end}}
+<h1 class="title">{{.Title}}</h1>
+<p class="meta">{{.Author}}</p>
+{{if gt (len .Chapters) 1}}<nav><h2>目录</h2><ol>
+{{range .Chapters}}<li><a href="#{{.ID}}">{{.Title}}</a></li>
+{{end}}</ol></nav>{{end}}
+{{range .Chapters}}<section id="{{.ID}}">
+{{if gt (len $.Chapters) 1}}<h1>{{.Title}}</h1>{{end}}
+<p class="meta">{{.Date}}</p>
+{{.Content}}
+</section>
+{{end}}</body>
+</html>
+`))
+
+type htmlChapter struct {
+	ID      string
+	Title   string
+	Date    string
+	Content template.HTML
+}
+
+// HTML 导出为可以离线打开的单个 HTML 文件
+func (b *Book) HTML() ([]byte, error) {
+	assets := b.assetsByName()
+	data := struct {
+		Title    string
+		Author   string
+		Style    template.CSS
+		Cover    template.URL
+		Chapters []htmlChapter
+	}{
+		Title:  b.Title,
+		Author: b.Author,
+		Style:  template.CSS(styleSheet()),
+	}
+	if b.Cover != nil {
+		data.Cover = template.URL(b.Cover.dataURI())
+	}
+
+	for i, chapter := range b.Chapters {
+		content, err := rewriteImages(chapter.HTML, func(img *goquery.Selection, src string) {
+			if a, ok := assets[src]; ok {
+				img.SetAttr("src", a.dataURI())
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		data.Chapters = append(data.Chapters, htmlChapter{
+			ID:    fmt.Sprintf("chapter-%d", i+1),
+			Title: chapter.Article.Title,
+			Date:  time.Time(chapter.Article.CreatedAt).Format(time.DateOnly),
+			// 正文已经过 Markdown 渲染器的清理
+			Content: template.HTML(content),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,243 @@
+package feed_ser
+
+import (
+	"blog/global"
+	"blog/models"
+	"blog/utils"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultFeedSize = 20
+
+// Feed 订阅源内容，XML 为序列化后的文档
+type Feed struct {
+	XML          []byte
+	LastModified time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Category    []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Links    []atomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Author   *atomAuthor `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string         `xml:"title"`
+	ID        string         `xml:"id"`
+	Link      atomLink       `xml:"link"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Author    *atomAuthor    `xml:"author,omitempty"`
+	Category  []atomCategory `xml:"category"`
+	Summary   string         `xml:"summary,omitempty"`
+	Content   atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// feedArticles 获取最新发布的文章，category 为空时不按分类过滤
+func feedArticles(category string) ([]models.Article, error) {
+	size := global.Config.Site.FeedSize
+	if size <= 0 {
+		size = defaultFeedSize
+	}
+	// 显式按创建时间排序，默认排序会把置顶文章排在最前
+	params := models.SearchParams{
+		PageInfo:    models.PageInfo{Page: 1, PageSize: size},
+		SortField:   "created_at",
+		WithContent: true,
+	}
+	if category != "" {
+		params.Category = []string{category}
+	}
+	results, err := models.NewArticleService().ArticleSearch(params)
+	if err != nil {
+		return nil, err
+	}
+	return results.Articles, nil
+}
+
+// siteURL 站点地址，不带结尾的斜杠
+func siteURL() string {
+	return strings.TrimRight(global.Config.Site.URL, "/")
+}
+
+// ArticleURL 文章在前台的地址
+func ArticleURL(id string) string {
+	return siteURL() + "/article/" + id
+}
+
+// feedTitle 订阅源标题，分类订阅在站点名称后附加分类
+func feedTitle(category string) string {
+	if category == "" {
+		return global.Config.Site.Title
+	}
+	return global.Config.Site.Title + " - " + category
+}
+
+// articleHTML 文章正文渲染后的 HTML，优先使用保存时的渲染结果，渲染失败时退回摘要
+func articleHTML(article models.Article) string {
+	if article.ContentHTML != "" {
+		return article.ContentHTML
+	}
+	html, err := utils.ConvertMarkdownToHTML(article.Content)
+	if err != nil {
+		global.Log.Error("utils.ConvertMarkdownToHTML() failed",
+			zap.String("article_id", article.ID),
+			zap.String("error", err.Error()),
+		)
+		return article.Abstract
+	}
+	return html
+}
+
+// lastModified 文章列表中最近的更新时间
+func lastModified(articles []models.Article) time.Time {
+	var latest time.Time
+	for _, article := range articles {
+		if updated := time.Time(article.UpdatedAt); updated.After(latest) {
+			latest = updated
+		}
+	}
+	return latest.UTC().Truncate(time.Second)
+}
+
+// BuildRSS 生成 RSS 2.0 订阅源
+func BuildRSS(category, selfURL string) (*Feed, error) {
+	articles, err := feedArticles(category)
+	if err != nil {
+		return nil, err
+	}
+	modified := lastModified(articles)
+
+	channel := rssChannel{
+		Title:       feedTitle(category),
+		Link:        siteURL(),
+		Description: global.Config.Site.Description,
+		AtomLink:    atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(articles)),
+	}
+	if !modified.IsZero() {
+		channel.LastBuildDate = modified.Format(time.RFC1123Z)
+	}
+	for _, article := range articles {
+		link := ArticleURL(article.ID)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       article.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Description: articleHTML(article),
+			Category:    article.Category,
+			PubDate:     time.Time(article.CreatedAt).Format(time.RFC1123Z),
+		})
+	}
+
+	data, err := xml.MarshalIndent(rss{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: channel}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &Feed{XML: append([]byte(xml.Header), data...), LastModified: modified}, nil
+}
+
+// BuildAtom 生成 Atom 订阅源
+func BuildAtom(category, selfURL string) (*Feed, error) {
+	articles, err := feedArticles(category)
+	if err != nil {
+		return nil, err
+	}
+	modified := lastModified(articles)
+
+	feed := atomFeed{
+		Title:    feedTitle(category),
+		Subtitle: global.Config.Site.Description,
+		ID:       selfURL,
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: siteURL(), Rel: "alternate", Type: "text/html"},
+		},
+		Updated: modified.Format(time.RFC3339),
+		Entries: make([]atomEntry, 0, len(articles)),
+	}
+	if global.Config.Site.Author != "" {
+		feed.Author = &atomAuthor{Name: global.Config.Site.Author}
+	}
+	for _, article := range articles {
+		link := ArticleURL(article.ID)
+		entry := atomEntry{
+			Title:     article.Title,
+			ID:        link,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: time.Time(article.CreatedAt).Format(time.RFC3339),
+			Updated:   time.Time(article.UpdatedAt).Format(time.RFC3339),
+			Author:    &atomAuthor{Name: article.UserName},
+			Summary:   article.Abstract,
+			Content:   atomContent{Type: "html", Value: articleHTML(article)},
+		}
+		for _, category := range article.Category {
+			entry.Category = append(entry.Category, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &Feed{XML: append([]byte(xml.Header), data...), LastModified: modified}, nil
+}
@@ -7,12 +7,15 @@ import (
 	"time"
 
 	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 const (
-	FieldLookCount    = "look_count"
-	FieldCommentCount = "comment_count"
+	FieldLookCount     = "look_count"
+	FieldCommentCount  = "comment_count"
+	FieldDiggCount     = "digg_count"
+	FieldCollectsCount = "collects_count"
 
 	ViewIPExpire       = 10 * time.Minute // IP访问记录过期时间
 	ViewBatchSize      = 100              // 批量更新阈值
@@ -22,6 +25,10 @@ const (
 	BloomFilterSize    = 100000          // 预期元素数量
 	BloomFalsePositive = 0.01            // 期望的误判率
 
+	RelatedArticlesExpire = time.Hour // 相关文章缓存过期时间
+
+	UnlockAttemptExpire = 10 * time.Minute // 文章密码尝试次数的统计窗口
+	UnlockAttemptMax    = 10               // 统计窗口内同一IP最多尝试的次数
 )
 
 // 获取文章统计数据的Redis键
@@ -89,6 +96,8 @@ func IncrArticleLookCount(articleID, ip string) error {
 		FieldLookCount,
 		1,
 	)
+	// 增加当天热度
+	pipe.ZIncrBy(ctx, GetHotBucketKey(time.Now()), HotWeightView, articleID)
 
 	// 执行Pipeline
 	_, err = pipe.Exec(ctx)
@@ -124,23 +133,23 @@ func GetArticleCommentCount(articleID string) (int64, error) {
 
 // 增加文章评论数
 func IncrArticleCommentCount(articleID string) error {
-	return global.Redis.HIncrBy(
-		context.Background(),
-		GetArticleStatsKey(articleID),
-		FieldCommentCount,
-		1,
-	).Err()
+	ctx := context.Background()
+	pipe := global.Redis.TxPipeline()
+	pipe.HIncrBy(ctx, GetArticleStatsKey(articleID), FieldCommentCount, 1)
+	pipe.ZIncrBy(ctx, GetHotBucketKey(time.Now()), HotWeightComment, articleID)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // 减少文章评论数
 func DecrArticleCommentCount(articleID string) error {
 	global.Log.Info("DecrArticleCommentCount", zap.String("articleID", articleID))
-	return global.Redis.HIncrBy(
-		context.Background(),
-		GetArticleStatsKey(articleID),
-		FieldCommentCount,
-		-1,
-	).Err()
+	ctx := context.Background()
+	pipe := global.Redis.TxPipeline()
+	pipe.HIncrBy(ctx, GetArticleStatsKey(articleID), FieldCommentCount, -1)
+	pipe.ZIncrBy(ctx, GetHotBucketKey(time.Now()), -HotWeightComment, articleID)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // 设置文章评论数
@@ -260,3 +269,193 @@ func DeleteArticleStats(articleID string) error {
 		GetArticleStatsKey(articleID),
 	).Err()
 }
+
+// 获取相关文章缓存的Redis键
+func GetRelatedArticlesKey(articleID string) string {
+	return BuildKey(ArticlePrefix, "related", articleID)
+}
+
+// 获取缓存的相关文章ID列表，未命中时返回 redis.Nil
+func GetRelatedArticles(articleID string) ([]byte, error) {
+	return global.Redis.Get(
+		context.Background(),
+		GetRelatedArticlesKey(articleID),
+	).Bytes()
+}
+
+// 缓存相关文章ID列表
+func SetRelatedArticles(articleID string, data []byte) error {
+	return global.Redis.Set(
+		context.Background(),
+		GetRelatedArticlesKey(articleID),
+		data,
+		RelatedArticlesExpire,
+	).Err()
+}
+
+// 删除相关文章缓存
+func DeleteRelatedArticles(articleID string) error {
+	return global.Redis.Del(
+		context.Background(),
+		GetRelatedArticlesKey(articleID),
+	).Err()
+}
+
+// 集合中加入用户并增加计数和当天热度，用户已存在时不重复计数，返回最新计数
+var interactionAddScript = redis.NewScript(`
+if redis.call("SADD", KEYS[1], ARGV[1]) == 1 then
+	redis.call("ZINCRBY", KEYS[3], ARGV[4], ARGV[3])
+	return redis.call("HINCRBY", KEYS[2], ARGV[2], 1)
+end
+return tonumber(redis.call("HGET", KEYS[2], ARGV[2]) or "0")
+`)
+
+// 集合中移除用户并减少计数和当天热度，用户不存在时不改变计数，返回最新计数
+var interactionRemoveScript = redis.NewScript(`
+if redis.call("SREM", KEYS[1], ARGV[1]) == 1 then
+	redis.call("ZINCRBY", KEYS[3], -ARGV[4], ARGV[3])
+	return redis.call("HINCRBY", KEYS[2], ARGV[2], -1)
+end
+return tonumber(redis.call("HGET", KEYS[2], ARGV[2]) or "0")
+`)
+
+// 获取文章点赞用户集合的Redis键
+func GetArticleDiggKey(articleID string) string {
+	return BuildKey(ArticlePrefix, "digg", articleID)
+}
+
+// 获取文章收藏用户集合的Redis键
+func GetArticleCollectKey(articleID string) string {
+	return BuildKey(ArticlePrefix, "collect", articleID)
+}
+
+// 获取用户收藏列表的Redis键，按收藏时间排序
+func GetUserCollectsKey(userID uint) string {
+	return BuildKey(UserPrefix, "collects", strconv.FormatUint(uint64(userID), 10))
+}
+
+// 点赞文章，返回最新点赞数
+func DiggArticle(articleID string, userID uint) (int64, error) {
+	return interactionAddScript.Run(
+		context.Background(),
+		global.Redis,
+		[]string{GetArticleDiggKey(articleID), GetArticleStatsKey(articleID), GetHotBucketKey(time.Now())},
+		userID,
+		FieldDiggCount,
+		articleID,
+		HotWeightDigg,
+	).Int64()
+}
+
+// 取消点赞，返回最新点赞数
+func UndiggArticle(articleID string, userID uint) (int64, error) {
+	return interactionRemoveScript.Run(
+		context.Background(),
+		global.Redis,
+		[]string{GetArticleDiggKey(articleID), GetArticleStatsKey(articleID), GetHotBucketKey(time.Now())},
+		userID,
+		FieldDiggCount,
+		articleID,
+		HotWeightDigg,
+	).Int64()
+}
+
+// 收藏文章，同时记录到用户的收藏列表，返回最新收藏数
+func CollectArticle(articleID string, userID uint) (int64, error) {
+	ctx := context.Background()
+	count, err := interactionAddScript.Run(
+		ctx,
+		global.Redis,
+		[]string{GetArticleCollectKey(articleID), GetArticleStatsKey(articleID), GetHotBucketKey(time.Now())},
+		userID,
+		FieldCollectsCount,
+		articleID,
+		HotWeightCollect,
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	// NX 保留首次收藏的时间
+	err = global.Redis.ZAddNX(ctx, GetUserCollectsKey(userID), redis.Z{
+		Score:  float64(time.Now().Unix()),
+		Member: articleID,
+	}).Err()
+	return count, err
+}
+
+// 取消收藏，同时从用户的收藏列表移除，返回最新收藏数
+func UncollectArticle(articleID string, userID uint) (int64, error) {
+	ctx := context.Background()
+	count, err := interactionRemoveScript.Run(
+		ctx,
+		global.Redis,
+		[]string{GetArticleCollectKey(articleID), GetArticleStatsKey(articleID), GetHotBucketKey(time.Now())},
+		userID,
+		FieldCollectsCount,
+		articleID,
+		HotWeightCollect,
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, global.Redis.ZRem(ctx, GetUserCollectsKey(userID), articleID).Err()
+}
+
+// 检查用户是否点赞、收藏了文章
+func GetArticleInteraction(articleID string, userID uint) (digged, collected bool, err error) {
+	ctx := context.Background()
+	pipe := global.Redis.Pipeline()
+	diggCmd := pipe.SIsMember(ctx, GetArticleDiggKey(articleID), userID)
+	collectCmd := pipe.SIsMember(ctx, GetArticleCollectKey(articleID), userID)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return false, false, err
+	}
+	return diggCmd.Val(), collectCmd.Val(), nil
+}
+
+// 获取用户收藏的全部文章ID，最近收藏的在前
+func GetUserCollects(userID uint) ([]string, error) {
+	return global.Redis.ZRevRange(context.Background(), GetUserCollectsKey(userID), 0, -1).Result()
+}
+
+// 删除文章的点赞、收藏记录，并从收藏过它的用户的收藏列表中移除
+func DeleteArticleInteractions(articleID string) error {
+	ctx := context.Background()
+
+	userIDs, err := global.Redis.SMembers(ctx, GetArticleCollectKey(articleID)).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := global.Redis.Pipeline()
+	for _, userID := range userIDs {
+		pipe.ZRem(ctx, BuildKey(UserPrefix, "collects", userID), articleID)
+	}
+	pipe.Del(ctx, GetArticleDiggKey(articleID), GetArticleCollectKey(articleID))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// 获取文章密码尝试次数的Redis键
+func GetUnlockAttemptsKey(articleID, ip string) string {
+	return BuildKey(ArticlePrefix, "unlock", articleID, ip)
+}
+
+// 增加同一IP尝试文章密码的次数，返回统计窗口内的次数
+func IncrUnlockAttempts(articleID, ip string) (int64, error) {
+	ctx := context.Background()
+	key := GetUnlockAttemptsKey(articleID, ip)
+	var incr *redis.IntCmd
+	// 计数不存在时先带过期时间创建，INCR 会保留已有的过期时间
+	_, err := global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, UnlockAttemptExpire)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
@@ -0,0 +1,133 @@
+package redis_ser
+
+import (
+	"blog/global"
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 热度权重，每次行为计入当天的热度分桶
+const (
+	HotWeightView    = 1
+	HotWeightComment = 5
+	HotWeightDigg    = 3
+	HotWeightCollect = 4
+
+	hotBucketLayout = "20060102" // 分桶日期格式
+	hotRankExpire   = time.Hour  // 合并后的排行过期时间，由定时任务提前刷新
+	hotBucketsKeep  = 30         // 分桶保留天数，与最长的排行窗口一致
+)
+
+// HotWindow 热度排行窗口，越早的分桶按 Decay 的天数次幂衰减
+type HotWindow struct {
+	Days  int
+	Decay float64
+}
+
+// HotWindows 支持的排行窗口
+var HotWindows = map[string]HotWindow{
+	"7d":  {Days: 7, Decay: 0.8},
+	"30d": {Days: 30, Decay: 0.93},
+}
+
+// 获取某一天热度分桶的Redis键
+func GetHotBucketKey(day time.Time) string {
+	return BuildKey(ArticlePrefix, "hot", day.Format(hotBucketLayout))
+}
+
+// 获取合并后热度排行的Redis键
+func GetHotRankKey(window string) string {
+	return BuildKey(ArticlePrefix, "hot", "rank", window)
+}
+
+// 将窗口内的分桶按衰减权重合并为排行
+func RefreshHotRank(window string) error {
+	w, ok := HotWindows[window]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	keys := make([]string, 0, w.Days)
+	weights := make([]float64, 0, w.Days)
+	for i := 0; i < w.Days; i++ {
+		keys = append(keys, GetHotBucketKey(now.AddDate(0, 0, -i)))
+		weights = append(weights, math.Pow(w.Decay, float64(i)))
+	}
+
+	ctx := context.Background()
+	rankKey := GetHotRankKey(window)
+	pipe := global.Redis.TxPipeline()
+	pipe.ZUnionStore(ctx, rankKey, &redis.ZStore{Keys: keys, Weights: weights, Aggregate: "SUM"})
+	// 剔除取消点赞、删除评论后热度不为正的文章
+	pipe.ZRemRangeByScore(ctx, rankKey, "-inf", "0")
+	pipe.Expire(ctx, rankKey, hotRankExpire)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// 获取热度排行前 size 篇文章的ID和分数，排行不存在时先合并
+func GetHotArticles(window string, size int) ([]redis.Z, error) {
+	ctx := context.Background()
+	rankKey := GetHotRankKey(window)
+
+	exists, err := global.Redis.Exists(ctx, rankKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if err := RefreshHotRank(window); err != nil {
+			return nil, err
+		}
+	}
+
+	return global.Redis.ZRevRangeWithScores(ctx, rankKey, 0, int64(size)-1).Result()
+}
+
+// 从所有分桶和排行中移除文章
+func RemoveHotArticle(articleID string) error {
+	ctx := context.Background()
+	now := time.Now()
+	pipe := global.Redis.Pipeline()
+	for i := 0; i < hotBucketsKeep; i++ {
+		pipe.ZRem(ctx, GetHotBucketKey(now.AddDate(0, 0, -i)), articleID)
+	}
+	for window := range HotWindows {
+		pipe.ZRem(ctx, GetHotRankKey(window), articleID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// 删除超出保留天数的热度分桶，返回删除的数量
+func ExpireHotBuckets() (int, error) {
+	ctx := context.Background()
+	prefix := BuildKey(ArticlePrefix, "hot") + ":"
+	earliest := time.Now().AddDate(0, 0, -hotBucketsKeep+1).Format(hotBucketLayout)
+
+	var expired []string
+	iter := global.Redis.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		day := strings.TrimPrefix(iter.Val(), prefix)
+		// 跳过合并后的排行
+		if _, err := time.Parse(hotBucketLayout, day); err != nil {
+			continue
+		}
+		// 日期格式固定，可以直接按字符串比较
+		if day < earliest {
+			expired = append(expired, iter.Val())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	return len(expired), global.Redis.Del(ctx, expired...).Err()
+}
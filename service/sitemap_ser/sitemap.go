@@ -0,0 +1,205 @@
+package sitemap_ser
+
+import (
+	"blog/global"
+	"blog/models"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	IndexName      = "sitemap.xml" // 入口文件，超过单个文件上限时为 sitemap 索引
+	maxURLs        = 50000         // 单个 sitemap 文件的 URL 上限
+	refreshMaxAge  = time.Hour     // 即使文章没有变化也定期重新生成
+	sitemapXMLNS   = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	lastModLayout  = "2006-01-02T15:04:05Z07:00"
+	partNameFormat = "sitemap-%d.xml"
+)
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []entry  `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// store 生成好的 sitemap 文件，文件名 -> 内容
+var store struct {
+	sync.RWMutex
+	files       map[string][]byte
+	generatedAt time.Time
+}
+
+// dirty 文章发生变化，下次定时任务需要重新生成
+var dirty atomic.Bool
+
+// refreshMu 避免定时任务和首次请求同时生成
+var refreshMu sync.Mutex
+
+// MarkDirty 标记文章已变化
+func MarkDirty() {
+	dirty.Store(true)
+}
+
+// Get 获取 sitemap 文件，尚未生成过时先生成
+func Get(name string) ([]byte, time.Time, bool) {
+	store.RLock()
+	generated := store.files != nil
+	store.RUnlock()
+
+	if !generated {
+		if err := Refresh(); err != nil {
+			global.Log.Error("生成 sitemap 失败", zap.String("error", err.Error()))
+			return nil, time.Time{}, false
+		}
+	}
+
+	store.RLock()
+	defer store.RUnlock()
+	data, ok := store.files[name]
+	return data, store.generatedAt, ok
+}
+
+// URL sitemap 中的一个地址，LastMod 为零值时不输出
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Build 按给定的地址生成 sitemap 文件，文件名 -> 内容，分片文件位于 /sitemaps/ 下。
+// 静态导出的页面路径与线上不同，由导出方自行列出地址
+func Build(site string, urls []URL) (map[string][]byte, error) {
+	entries := make([]entry, 0, len(urls))
+	for _, u := range urls {
+		e := entry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			e.LastMod = u.LastMod.Format(lastModLayout)
+		}
+		entries = append(entries, e)
+	}
+	return build(strings.TrimRight(site, "/"), entries)
+}
+
+// RefreshIfNeeded 文章有变化或距离上次生成超过一小时时重新生成
+func RefreshIfNeeded() error {
+	store.RLock()
+	stale := time.Since(store.generatedAt) > refreshMaxAge
+	store.RUnlock()
+
+	if !dirty.Load() && !stale {
+		return nil
+	}
+	return Refresh()
+}
+
+// Refresh 遍历所有已发布文章和分类，重新生成 sitemap
+func Refresh() error {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	site := strings.TrimRight(global.Config.Site.URL, "/")
+	if site == "" {
+		return errors.New("未配置站点地址 site.url")
+	}
+	// 先清除标记，生成期间的变化留给下一次
+	dirty.Store(false)
+
+	entries := []entry{{Loc: site + "/"}}
+
+	var categories []models.CategoryModel
+	if err := global.DB.Find(&categories).Error; err != nil {
+		dirty.Store(true)
+		return fmt.Errorf("获取分类失败: %w", err)
+	}
+	for _, category := range categories {
+		entries = append(entries, entry{Loc: site + "/category/" + url.PathEscape(category.Name)})
+	}
+
+	err := models.NewArticleService().ArticleScan([]string{"updated_at"}, func(articles []models.Article) error {
+		for _, article := range articles {
+			entries = append(entries, entry{
+				Loc:     site + "/article/" + article.ID,
+				LastMod: time.Time(article.UpdatedAt).Format(lastModLayout),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		dirty.Store(true)
+		return err
+	}
+
+	files, err := build(site, entries)
+	if err != nil {
+		dirty.Store(true)
+		return err
+	}
+
+	store.Lock()
+	store.files = files
+	store.generatedAt = time.Now()
+	store.Unlock()
+
+	global.Log.Info("生成 sitemap 成功", zap.Int("urls", len(entries)), zap.Int("files", len(files)))
+	return nil
+}
+
+// build 生成 sitemap 文件，URL 数量超过上限时拆分为多个文件并生成索引
+func build(site string, entries []entry) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if len(entries) <= maxURLs {
+		data, err := marshal(urlSet{XMLNS: sitemapXMLNS, URLs: entries})
+		if err != nil {
+			return nil, err
+		}
+		files[IndexName] = data
+		return files, nil
+	}
+
+	now := time.Now().Format(lastModLayout)
+	index := sitemapIndex{XMLNS: sitemapXMLNS}
+	for part := 1; len(entries) > 0; part++ {
+		n := min(len(entries), maxURLs)
+		name := fmt.Sprintf(partNameFormat, part)
+		data, err := marshal(urlSet{XMLNS: sitemapXMLNS, URLs: entries[:n]})
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+		index.Sitemaps = append(index.Sitemaps, entry{Loc: site + "/sitemaps/" + name, LastMod: now})
+		entries = entries[n:]
+	}
+
+	data, err := marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	files[IndexName] = data
+	return files, nil
+}
+
+func marshal(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
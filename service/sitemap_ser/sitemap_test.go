@@ -0,0 +1,74 @@
+package sitemap_ser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	const site = "https://example.com"
+	tests := []struct {
+		name  string
+		urls  int
+		parts []int // 每个分片文件中的 URL 数量，为空表示不拆分
+	}{
+		{name: "没有文章", urls: 1},
+		{name: "刚好达到上限", urls: maxURLs},
+		{name: "超出上限一个", urls: maxURLs + 1, parts: []int{maxURLs, 1}},
+		{name: "拆分为三个文件", urls: 2*maxURLs + 10, parts: []int{maxURLs, maxURLs, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := make([]entry, tt.urls)
+			for i := range entries {
+				entries[i] = entry{Loc: fmt.Sprintf("%s/article/%d", site, i)}
+			}
+			files, err := build(site, entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != len(tt.parts)+1 {
+				t.Fatalf("生成了 %d 个文件, want %d", len(files), len(tt.parts)+1)
+			}
+
+			if len(tt.parts) == 0 {
+				var set urlSet
+				if err := xml.Unmarshal(files[IndexName], &set); err != nil {
+					t.Fatal(err)
+				}
+				if len(set.URLs) != tt.urls {
+					t.Errorf("%s 中有 %d 个 URL, want %d", IndexName, len(set.URLs), tt.urls)
+				}
+				return
+			}
+
+			var index sitemapIndex
+			if err := xml.Unmarshal(files[IndexName], &index); err != nil {
+				t.Fatalf("%s 不是 sitemap 索引: %v", IndexName, err)
+			}
+			if len(index.Sitemaps) != len(tt.parts) {
+				t.Fatalf("索引中有 %d 个文件, want %d", len(index.Sitemaps), len(tt.parts))
+			}
+			next := 0
+			for i, want := range tt.parts {
+				name := fmt.Sprintf(partNameFormat, i+1)
+				if loc := index.Sitemaps[i].Loc; loc != site+"/sitemaps/"+name {
+					t.Errorf("索引中第 %d 个文件为 %s", i+1, loc)
+				}
+				var set urlSet
+				if err := xml.Unmarshal(files[name], &set); err != nil {
+					t.Fatalf("解析 %s 失败: %v", name, err)
+				}
+				if len(set.URLs) != want {
+					t.Fatalf("%s 中有 %d 个 URL, want %d", name, len(set.URLs), want)
+				}
+				// 按顺序拆分，不重复也不遗漏
+				if set.URLs[0] != entries[next] || set.URLs[want-1] != entries[next+want-1] {
+					t.Errorf("%s 中的 URL 顺序错误", name)
+				}
+				next += want
+			}
+		})
+	}
+}
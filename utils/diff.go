@@ -0,0 +1,143 @@
+package utils
+
+import "strings"
+
+// DiffOp 差异类型
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"  // 未变化
+	DiffInsert DiffOp = "insert" // 新增
+	DiffDelete DiffOp = "delete" // 删除
+)
+
+// DiffLine 行级差异
+type DiffLine struct {
+	Op      DiffOp `json:"op"`
+	OldLine int    `json:"old_line,omitempty"` // 旧文本中的行号，从1开始
+	NewLine int    `json:"new_line,omitempty"` // 新文本中的行号，从1开始
+	Content string `json:"content"`
+}
+
+// splitLines 按行切分文本
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffMaxEdits Myers 算法最多搜索的编辑距离。回溯需要保存每一轮的状态，内存随编辑距离平方增长，
+// 超出后不再计算最短差异，除首尾相同的行外整体作为删除和新增
+const diffMaxEdits = 1000
+
+// DiffLines 使用 Myers 算法计算两段文本的行级差异，差异过大时退化为整段替换
+func DiffLines(oldText, newText string) []DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+	n, m := len(a), len(b)
+	offset := n + m
+
+	// v[offset+k] 记录对角线 k 上能到达的最远 x
+	v := make([]int, 2*offset+2)
+	// trace[d] 保存第 d 轮开始前 [-d, d] 区间的 v，用于回溯
+	var trace [][]int
+
+	for d := 0; d <= offset; d++ {
+		if d > diffMaxEdits {
+			return diffReplace(a, b)
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return diffBacktrack(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+// diffBacktrack 根据搜索轨迹回溯出差异列表
+func diffBacktrack(trace [][]int, a, b []string) []DiffLine {
+	x, y := len(a), len(b)
+	var reversed []DiffLine
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Op: DiffEqual, OldLine: x, NewLine: y, Content: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, DiffLine{Op: DiffInsert, NewLine: y, Content: b[y-1]})
+		} else {
+			reversed = append(reversed, DiffLine{Op: DiffDelete, OldLine: x, Content: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, DiffLine{Op: DiffEqual, OldLine: x, NewLine: y, Content: a[x-1]})
+		x--
+		y--
+	}
+
+	result := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		result[len(reversed)-1-i] = line
+	}
+	return result
+}
+
+// diffReplace 保留首尾相同的行，中间部分整体作为删除和新增
+func diffReplace(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	prefix := 0
+	for prefix < n && prefix < m && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && a[n-1-suffix] == b[m-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, n+m-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		result = append(result, DiffLine{Op: DiffEqual, OldLine: i + 1, NewLine: i + 1, Content: a[i]})
+	}
+	for i := prefix; i < n-suffix; i++ {
+		result = append(result, DiffLine{Op: DiffDelete, OldLine: i + 1, Content: a[i]})
+	}
+	for i := prefix; i < m-suffix; i++ {
+		result = append(result, DiffLine{Op: DiffInsert, NewLine: i + 1, Content: b[i]})
+	}
+	for i := suffix; i > 0; i-- {
+		result = append(result, DiffLine{Op: DiffEqual, OldLine: n - i + 1, NewLine: m - i + 1, Content: a[n-i]})
+	}
+	return result
+}
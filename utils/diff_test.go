@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []DiffLine
+	}{
+		{name: "都为空", old: "", new: "", want: []DiffLine{}},
+		{
+			name: "新增全文",
+			old:  "",
+			new:  "a\nb",
+			want: []DiffLine{
+				{Op: DiffInsert, NewLine: 1, Content: "a"},
+				{Op: DiffInsert, NewLine: 2, Content: "b"},
+			},
+		},
+		{
+			name: "删除全文",
+			old:  "a",
+			new:  "",
+			want: []DiffLine{{Op: DiffDelete, OldLine: 1, Content: "a"}},
+		},
+		{
+			name: "没有变化",
+			old:  "a\nb",
+			new:  "a\r\nb",
+			want: []DiffLine{
+				{Op: DiffEqual, OldLine: 1, NewLine: 1, Content: "a"},
+				{Op: DiffEqual, OldLine: 2, NewLine: 2, Content: "b"},
+			},
+		},
+		{
+			name: "修改中间一行",
+			old:  "a\nb\nc",
+			new:  "a\nx\nc",
+			want: []DiffLine{
+				{Op: DiffEqual, OldLine: 1, NewLine: 1, Content: "a"},
+				{Op: DiffDelete, OldLine: 2, Content: "b"},
+				{Op: DiffInsert, NewLine: 2, Content: "x"},
+				{Op: DiffEqual, OldLine: 3, NewLine: 3, Content: "c"},
+			},
+		},
+		{
+			name: "插入和删除",
+			old:  "a\nb\nc\nd",
+			new:  "b\nc\ne\nd",
+			want: []DiffLine{
+				{Op: DiffDelete, OldLine: 1, Content: "a"},
+				{Op: DiffEqual, OldLine: 2, NewLine: 1, Content: "b"},
+				{Op: DiffEqual, OldLine: 3, NewLine: 2, Content: "c"},
+				{Op: DiffInsert, NewLine: 3, Content: "e"},
+				{Op: DiffEqual, OldLine: 4, NewLine: 4, Content: "d"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesTooManyEdits(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < diffMaxEdits; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("old %d", i))
+		newLines = append(newLines, fmt.Sprintf("new %d", i))
+	}
+	oldText := "head\n" + strings.Join(oldLines, "\n") + "\ntail"
+	newText := "head\n" + strings.Join(newLines, "\n") + "\ntail"
+
+	got := DiffLines(oldText, newText)
+	if len(got) != 2*diffMaxEdits+2 {
+		t.Fatalf("len(DiffLines()) = %d, want %d", len(got), 2*diffMaxEdits+2)
+	}
+	if got[0] != (DiffLine{Op: DiffEqual, OldLine: 1, NewLine: 1, Content: "head"}) {
+		t.Errorf("第一行 = %v, 首部相同的行应当保留", got[0])
+	}
+	if last := got[len(got)-1]; last != (DiffLine{Op: DiffEqual, OldLine: diffMaxEdits + 2, NewLine: diffMaxEdits + 2, Content: "tail"}) {
+		t.Errorf("最后一行 = %v, 尾部相同的行应当保留", last)
+	}
+	for i, line := range got[1 : diffMaxEdits+1] {
+		if line != (DiffLine{Op: DiffDelete, OldLine: i + 2, Content: oldLines[i]}) {
+			t.Fatalf("第 %d 行 = %v, 中间部分应当整体删除", i+2, line)
+		}
+	}
+	for i, line := range got[diffMaxEdits+1 : 2*diffMaxEdits+1] {
+		if line != (DiffLine{Op: DiffInsert, NewLine: i + 2, Content: newLines[i]}) {
+			t.Fatalf("第 %d 行 = %v, 中间部分应当整体新增", i+2, line)
+		}
+	}
+}
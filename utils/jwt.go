@@ -28,6 +28,25 @@ type RefreshClaims struct {
 	jwt.StandardClaims
 }
 
+// ArticleClaims 密码保护文章的解锁令牌
+type ArticleClaims struct {
+	ArticleID   string `json:"article_id"`
+	Fingerprint string `json:"fingerprint"` // 访问密码哈希的摘要，修改密码后已签发的令牌失效
+	jwt.StandardClaims
+}
+
+// ArticleTokenExpires 文章解锁令牌的有效期
+const ArticleTokenExpires = 2 * time.Hour
+
+// articleTokenKey 解锁令牌与登录令牌使用不同的密钥，避免被当作登录令牌使用
+func articleTokenKey() []byte {
+	return []byte(global.Config.Jwt.Secret + ":article")
+}
+
+func passwordFingerprint(passwordHash string) string {
+	return Md5([]byte(passwordHash))[:16]
+}
+
 // GenerateAccessToken 生成 Access Token
 func GenerateAccessToken(payload PayLoad) (string, error) {
 	claims := CustomClaims{
@@ -52,6 +71,34 @@ func GenerateRefreshToken(userID uint) (string, error) {
 	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(global.Config.Jwt.Secret))
 }
 
+// GenerateArticleToken 生成文章解锁令牌
+func GenerateArticleToken(articleID, passwordHash string) (string, error) {
+	claims := ArticleClaims{
+		ArticleID:   articleID,
+		Fingerprint: passwordFingerprint(passwordHash),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ArticleTokenExpires).Unix(),
+			Issuer:    global.Config.Jwt.Issuer,
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(articleTokenKey())
+}
+
+// CheckArticleToken 校验文章解锁令牌是否属于该文章且在修改密码之后签发
+func CheckArticleToken(tokenString, articleID, passwordHash string) bool {
+	var claims ArticleClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return articleTokenKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return false
+	}
+	return claims.ArticleID == articleID && claims.Fingerprint == passwordFingerprint(passwordHash)
+}
+
 // ParseToken 解析  Token
 func ParseToken(tokenString string) (*CustomClaims, error) {
 	var claims CustomClaims
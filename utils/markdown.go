@@ -1,14 +1,23 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"regexp"
 	"strings"
+	"unicode"
 
 	"blog/global"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/PuerkitoBio/goquery"
-	"github.com/russross/blackfriday"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
 	"go.uber.org/zap"
 )
 
@@ -16,39 +25,175 @@ var (
 	ErrEmptyContent = errors.New("内容不能为空")
 )
 
-// ConvertMarkdownToHTML 将 Markdown 内容转换为 HTML 并移除可能的恶意脚本标签
+// markdownConverter Markdown 渲染器：GFM 表格、任务列表、删除线、自动链接，脚注，
+// 代码块使用 chroma 按 class 高亮（样式表由前端提供），公式与 mermaid 原样输出
+var markdownConverter = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+		extension.Footnote,
+		highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		),
+		&markdownExt{},
+	),
+	// 允许原始 HTML，统一交给 markdownPolicy 清理
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// markdownPolicy 在 UGC 策略基础上放行渲染器生成的 class、脚注和任务列表属性
+var markdownPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).
+		OnElements("pre", "code", "span", "div", "a", "sup", "ol", "li")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).
+		OnElements("a", "div", "sup")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	return p
+}()
+
+// renderMarkdownHTML 将 Markdown 渲染为 HTML 并按 markdownPolicy 清理
+func renderMarkdownHTML(content string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := markdownConverter.Convert([]byte(content), &buf)
+	if err != nil {
+		return nil, err
+	}
+	return markdownPolicy.SanitizeBytes(buf.Bytes()), nil
+}
+
+// ConvertMarkdownToHTML 将 Markdown 内容转换为清理后的 HTML
 func ConvertMarkdownToHTML(content string) (string, error) {
 	if strings.TrimSpace(content) == "" {
 		return "", ErrEmptyContent
 	}
 
-	// 转换 Markdown 为 HTML
-	unsafe := blackfriday.MarkdownCommon([]byte(content))
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(unsafe)))
+	html, err := renderMarkdownHTML(content)
 	if err != nil {
-		global.Log.Error("解析 HTML 文档失败",
+		global.Log.Error("渲染 Markdown 失败",
 			zap.String("error", err.Error()),
 			zap.String("content", content[:min(len(content), 100)]), // 只记录前100个字符
 		)
 		return "", err
+	}
+	return string(html), nil
+}
+
+// readingSpeed 每分钟阅读的字数，中文按字、英文按单词计算
+const readingSpeed = 300
+
+// TocItem 目录项，Children 为下一级标题
+type TocItem struct {
+	Level    int        `json:"level"`
+	Text     string     `json:"text"`
+	Anchor   string     `json:"anchor"`
+	Children []*TocItem `json:"children,omitempty"`
+}
 
+// RenderedMarkdown 渲染后的文章内容
+type RenderedMarkdown struct {
+	HTML        string     // 清理后的 HTML，标题带有锚点 id
+	Toc         []*TocItem // 目录树
+	WordCount   int        // 字数
+	ReadingTime int        // 预计阅读时间，单位分钟
+}
+
+// RenderMarkdown 将 Markdown 渲染为清理后的 HTML，为标题生成锚点，并提取目录、字数和阅读时间
+func RenderMarkdown(content string) (*RenderedMarkdown, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyContent
 	}
 
-	// 移除所有脚本标签以提高安全性
-	doc.Find("script").Remove()
+	safe, err := renderMarkdownHTML(content)
+	if err != nil {
+		return nil, fmt.Errorf("渲染 Markdown 失败: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(safe))
+	if err != nil {
+		return nil, fmt.Errorf("解析 HTML 文档失败: %w", err)
+	}
 
-	// 获取处理后的 HTML
-	html, err := doc.Html()
+	// 为标题生成锚点并构建目录树
+	var toc []*TocItem
+	var stack []*TocItem
+	anchors := make(map[string]int)
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, heading *goquery.Selection) {
+		text := strings.TrimSpace(heading.Text())
+		anchor := headingAnchor(text)
+		if n := anchors[anchor]; n > 0 {
+			anchors[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			anchors[anchor] = 1
+		}
+		heading.SetAttr("id", anchor)
+
+		item := &TocItem{Level: int(goquery.NodeName(heading)[1] - '0'), Text: text, Anchor: anchor}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			toc = append(toc, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+	})
+
+	html, err := doc.Find("body").Html()
 	if err != nil {
-		global.Log.Error("生成 HTML 失败",
-			zap.String("error", err.Error()),
-			zap.String("content", content[:min(len(content), 100)]),
-		)
-		return "", err
+		return nil, fmt.Errorf("生成 HTML 失败: %w", err)
 	}
 
+	words := countWords(doc.Text())
+	return &RenderedMarkdown{
+		HTML:        html,
+		Toc:         toc,
+		WordCount:   words,
+		ReadingTime: (words + readingSpeed - 1) / readingSpeed,
+	}, nil
+}
+
+// headingAnchor 根据标题文本生成锚点，保留字母、数字和汉字，空白替换为连字符
+func headingAnchor(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
 
-	return html, nil
+// countWords 统计字数，每个汉字计一个字，连续的字母数字计一个单词
+func countWords(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			count++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				count++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return count
 }
 
 // ConvertHTMLToMarkdown 将 HTML 内容转换回 Markdown 格式
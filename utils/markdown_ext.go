@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Markdown 扩展：KaTeX 公式与 mermaid 图表。
+// 两者都只在服务端原样输出源码，由前端的 KaTeX auto-render 和 mermaid 负责渲染。
+
+var (
+	kindMath        = ast.NewNodeKind("Math")
+	kindMathBlock   = ast.NewNodeKind("MathBlock")
+	kindMermaidCode = ast.NewNodeKind("MermaidCode")
+)
+
+// mathNode 行内公式，$..$ 为行内公式，$$..$$ 为行间公式
+type mathNode struct {
+	ast.BaseInline
+	Value   []byte
+	Display bool
+}
+
+func (n *mathNode) Kind() ast.NodeKind { return kindMath }
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.Value)}, nil)
+}
+
+// mathBlockNode 独占多行的 $$ 公式块
+type mathBlockNode struct {
+	ast.BaseBlock
+}
+
+func (n *mathBlockNode) Kind() ast.NodeKind { return kindMathBlock }
+
+func (n *mathBlockNode) IsRaw() bool { return true }
+
+func (n *mathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mermaidNode 语言为 mermaid 的代码块，不做语法高亮
+type mermaidNode struct {
+	ast.BaseBlock
+}
+
+func (n *mermaidNode) Kind() ast.NodeKind { return kindMermaidCode }
+
+func (n *mermaidNode) IsRaw() bool { return true }
+
+func (n *mermaidNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathInlineParser 解析行内公式，为避免误伤金额，$ 内侧不能是空白，结束的 $ 后不能紧跟数字
+type mathInlineParser struct{}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = 2
+	}
+
+	closer := bytes.Repeat([]byte{'$'}, delim)
+	for i := delim; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if !bytes.HasPrefix(line[i:], closer) {
+			continue
+		}
+		value := line[delim:i]
+		end := i + delim
+		if len(value) == 0 || util.IsSpace(value[0]) || util.IsSpace(value[len(value)-1]) {
+			return nil
+		}
+		if delim == 1 && end < len(line) && util.IsNumeric(line[end]) {
+			return nil
+		}
+		block.Advance(end)
+		return &mathNode{Value: append([]byte(nil), value...), Display: delim == 2}
+	}
+	return nil
+}
+
+// mathBlockParser 解析以单独一行 $$ 开始和结束的公式块
+type mathBlockParser struct{}
+
+func (b *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (b *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.Equal(bytes.TrimSpace(line[pos:]), []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - 1)
+	return &mathBlockNode{}, parser.NoChildren
+}
+
+func (b *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if bytes.Equal(bytes.TrimSpace(line), []byte("$$")) {
+		reader.Advance(segment.Len())
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return parser.Continue | parser.NoChildren
+}
+
+func (b *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (b *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mermaidTransformer 把 mermaid 代码块替换为 mermaidNode，使其绕过语法高亮
+type mermaidTransformer struct{}
+
+func (t *mermaidTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var blocks []*ast.FencedCodeBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if code, ok := n.(*ast.FencedCodeBlock); ok && entering {
+			if string(code.Language(reader.Source())) == "mermaid" {
+				blocks = append(blocks, code)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, code := range blocks {
+		node := &mermaidNode{}
+		node.SetLines(code.Lines())
+		code.Parent().ReplaceChild(code.Parent(), code, node)
+	}
+}
+
+// markdownExtRenderer 输出公式和 mermaid 节点
+type markdownExtRenderer struct{}
+
+func (r *markdownExtRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.renderMath)
+	reg.Register(kindMathBlock, r.renderMathBlock)
+	reg.Register(kindMermaidCode, r.renderMermaid)
+}
+
+// renderMath 行内公式使用 \( \) 定界，行间公式使用 \[ \] 定界，与 KaTeX auto-render 的默认配置一致
+func (r *markdownExtRenderer) renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mathNode)
+	if n.Display {
+		_, _ = w.WriteString(`<span class="math display">\[`)
+		_, _ = w.Write(util.EscapeHTML(n.Value))
+		_, _ = w.WriteString(`\]</span>`)
+	} else {
+		_, _ = w.WriteString(`<span class="math inline">\(`)
+		_, _ = w.Write(util.EscapeHTML(n.Value))
+		_, _ = w.WriteString(`\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *markdownExtRenderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<div class="math display">\[` + "\n")
+	writeRawLines(w, source, node)
+	_, _ = w.WriteString("\\]</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *markdownExtRenderer) renderMermaid(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<pre class="mermaid">`)
+	writeRawLines(w, source, node)
+	_, _ = w.WriteString("</pre>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// writeRawLines 转义后原样输出节点的源码行
+func writeRawLines(w util.BufWriter, source []byte, node ast.Node) {
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(line.Value(source)))
+	}
+}
+
+// markdownExt 注册公式和 mermaid 扩展
+type markdownExt struct{}
+
+func (e *markdownExt) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 700)),
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 500)),
+		parser.WithASTTransformers(util.Prioritized(&mermaidTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&markdownExtRenderer{}, 100),
+	))
+}
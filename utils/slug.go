@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// slugMaxLength slug 的最大长度，超出时在单词边界截断
+const slugMaxLength = 80
+
+// Slugify 将标题转换为 URL 中可读的 slug，汉字转换为不带声调的拼音，
+// 字母数字转为小写保留，其余字符作为分隔符，如 "Go 并发编程" -> "go-bing-fa-bian-cheng"
+func Slugify(title string) string {
+	args := pinyin.NewArgs()
+	words := make([]string, 0)
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range title {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			flush()
+			words = append(words, pinyin.LazyPinyin(string(r), args)...)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := ""
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if len(slug)+len(w)+1 > slugMaxLength && slug != "" {
+			break
+		}
+		if slug != "" {
+			slug += "-"
+		}
+		slug += w
+	}
+	if len(slug) > slugMaxLength {
+		slug = slug[:slugMaxLength]
+	}
+	return slug
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "Go 并发编程", want: "go-bing-fa-bian-cheng"},
+		{title: "Go 教程 2", want: "go-jiao-cheng-2"},
+		{title: "Hello, World!", want: "hello-world"},
+		{title: "  C++ / Rust  ", want: "c-rust"},
+		{title: "「中文」标点", want: "zhong-wen-biao-dian"},
+		{title: "Ünïcode", want: "n-code"},
+		{title: "!!!", want: ""},
+		{title: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyMaxLength(t *testing.T) {
+	// 在单词边界截断
+	title := strings.Repeat("abcdefghi ", 10)
+	if got, want := Slugify(title), strings.TrimSuffix(strings.Repeat("abcdefghi-", 8), "-"); got != want {
+		t.Errorf("Slugify() = %q, want %q", got, want)
+	}
+	// 单个单词超长时直接截断
+	if got := Slugify(strings.Repeat("a", 100)); got != strings.Repeat("a", slugMaxLength) {
+		t.Errorf("Slugify() = %q, want %d 个 a", got, slugMaxLength)
+	}
+}